@@ -94,6 +94,18 @@ const (
 	credentialSpecPrefix = "credentialspec"
 
 	credentialSpecDomainlessPrefix = credentialSpecPrefix + "domainless"
+
+	// seLinuxLabelPrefix is the prefix of the security options that configure the SELinux
+	// labels of a container. Docker accepts both "label=" and the legacy "label:" form.
+	seLinuxLabelPrefix = "label"
+
+	// webIdentityTokenFileEnvVarName is the environment variable the AWS SDKs read the path of
+	// the web identity token from
+	webIdentityTokenFileEnvVarName = "AWS_WEB_IDENTITY_TOKEN_FILE"
+
+	// containerTagLabelPrefix is the prefix of the docker labels that carry container level
+	// tags. The tag key is the remainder of the label name.
+	containerTagLabelPrefix = "com.amazonaws.ecs.tag."
 )
 
 var (
@@ -138,6 +150,8 @@ type ManagedAgentState struct {
 	Reason string `json:"reason,omitempty"`
 	// LastStartedAt is the timestamp when the status last went from PENDING->RUNNING
 	LastStartedAt time.Time `json:"lastStartedAt,omitempty"`
+	// LastStatusChange is the timestamp when the status of the managed agent last changed
+	LastStatusChange time.Time `json:"lastStatusChange,omitempty"`
 	// Metadata holds metadata about the managed agent
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 	// InitFailed indicates if exec agent initialization failed
@@ -163,6 +177,9 @@ type Container struct {
 	TaskARNUnsafe string `json:"taskARN"`
 	// DependsOnUnsafe is the field which specifies the ordering for container startup and shutdown.
 	DependsOnUnsafe []DependsOn `json:"dependsOn,omitempty"`
+	// UnmetDependencyUnsafe is the ordering dependency that could never be satisfied, causing
+	// the container to be stopped without being started.
+	UnmetDependencyUnsafe *UnmetDependency `json:"unmetDependency,omitempty"`
 	// ManagedAgentsUnsafe presently contains only the executeCommandAgent
 	ManagedAgentsUnsafe []ManagedAgent `json:"managedAgents,omitempty"`
 	// V3EndpointID is a container identifier used to construct v3 metadata endpoint; it's unique among
@@ -174,6 +191,9 @@ type Container struct {
 	ImageID string
 	// ImageDigest is the sha-256 digest of the container image as pulled from the repository
 	ImageDigest string
+	// ExpectedImageDigest is the digest resolved for the container image before it was
+	// pulled. It is only set when the digest of the pulled image differs from it.
+	ExpectedImageDigest string `json:"ExpectedImageDigest,omitempty"`
 	// Command is the command to run in the container which is specified in the task definition
 	Command []string
 	// CPU is the cpu limitation of the container which is specified in the task definition
@@ -323,6 +343,10 @@ type Container struct {
 	// pause container
 	ContainerTornDownUnsafe bool `json:"containerTornDown"`
 
+	// StopRequestedUnsafe is set to true once the agent has asked the runtime to stop the
+	// container, which tells a container that was stopped apart from one that exited on its own.
+	StopRequestedUnsafe bool `json:"stopRequested,omitempty"`
+
 	createdAt time.Time
 	// StartedAtUnsafe specifies the started at time of the container.
 	// It is exposed outside this container package so that it is marshalled/unmarshalled in JSON body while
@@ -333,6 +357,12 @@ type Container struct {
 	// invoked.
 	setStartedAtOnce sync.Once
 	finishedAt       time.Time
+	// imageCreatedAt is the creation time of the container's image from the image inspect
+	imageCreatedAt time.Time
+	// lastStats is the most recent docker stats sample collected for the container
+	lastStats *types.StatsJSON
+	// initPID is the host PID of the container's init process from the last docker inspect
+	initPID int
 
 	labels map[string]string
 
@@ -362,6 +392,13 @@ type DependsOn struct {
 	Condition     string `json:"condition"`
 }
 
+// UnmetDependency is an ordering dependency of a container that could never be satisfied
+type UnmetDependency struct {
+	DependsOn
+	// Reason describes why the dependency could not be satisfied
+	Reason string `json:"reason"`
+}
+
 type ContainerRestartAggregationDataForStats struct {
 	LastRestartDetectedAt     time.Time       `json:"LastRestartDetectedAt,omitempty"`
 	LastStatBeforeLastRestart types.StatsJSON `json:"LastStatBeforeLastRestart,omitempty"`
@@ -707,6 +744,39 @@ func (c *Container) SetFinishedAt(finishedAt time.Time) {
 	c.finishedAt = finishedAt
 }
 
+// SetImageCreatedAt sets the creation time of the container's image
+func (c *Container) SetImageCreatedAt(imageCreatedAt time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.imageCreatedAt = imageCreatedAt
+}
+
+// GetImageCreatedAt returns the creation time of the container's image
+func (c *Container) GetImageCreatedAt() time.Time {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.imageCreatedAt
+}
+
+// SetInitPID sets the host PID of the container's init process
+func (c *Container) SetInitPID(pid int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.initPID = pid
+}
+
+// GetInitPID returns the host PID of the container's init process, it's zero when
+// the container isn't running
+func (c *Container) GetInitPID() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.initPID
+}
+
 // GetCreatedAt sets the timestamp for container's creation time
 func (c *Container) GetCreatedAt() time.Time {
 	c.lock.RLock()
@@ -763,6 +833,40 @@ func (c *Container) SetImageDigest(ImageDigest string) {
 	c.ImageDigest = ImageDigest
 }
 
+// SetPulledImageDigest sets the ImageDigest for a container to the digest of the pulled
+// image. If a different digest was resolved for the container before the pull, that digest
+// is retained as the expected image digest.
+func (c *Container) SetPulledImageDigest(imageDigest string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if imageDigest == "" || imageDigest == c.ImageDigest {
+		return
+	}
+	if c.ImageDigest != "" {
+		c.ExpectedImageDigest = c.ImageDigest
+	}
+	c.ImageDigest = imageDigest
+}
+
+// GetExpectedImageDigest gets the image digest resolved for a container before the image
+// was pulled, if it differs from the digest of the pulled image
+func (c *Container) GetExpectedImageDigest() string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.ExpectedImageDigest
+}
+
+// ClearExpectedImageDigest clears the image digest resolved for a container before the image
+// was pulled, once the change of digest has been reported
+func (c *Container) ClearExpectedImageDigest() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.ExpectedImageDigest = ""
+}
+
 // GetImageDigest gets the ImageDigest for a container
 func (c *Container) GetImageDigest() string {
 	c.lock.RLock()
@@ -1180,6 +1284,22 @@ func (c *Container) SetDependsOn(dependsOn []DependsOn) {
 	c.DependsOnUnsafe = dependsOn
 }
 
+// GetUnmetDependency returns the ordering dependency that could never be satisfied
+func (c *Container) GetUnmetDependency() *UnmetDependency {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.UnmetDependencyUnsafe
+}
+
+// SetUnmetDependency sets the ordering dependency that could never be satisfied
+func (c *Container) SetUnmetDependency(unmetDependency *UnmetDependency) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.UnmetDependencyUnsafe = unmetDependency
+}
+
 // DependsOnContainer checks whether a container depends on another container.
 func (c *Container) DependsOnContainer(name string) bool {
 	c.lock.RLock()
@@ -1232,6 +1352,25 @@ func (c *Container) GetLogDriver() string {
 	return hostConfig.LogConfig.Type
 }
 
+// GetDNSOptions returns the resolver options set in the container's host config.
+func (c *Container) GetDNSOptions() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.DockerConfig.HostConfig == nil {
+		return nil
+	}
+
+	hostConfig := &dockercontainer.HostConfig{}
+	err := json.Unmarshal([]byte(*c.DockerConfig.HostConfig), hostConfig)
+	if err != nil {
+		seelog.Warnf("Encountered error when trying to get dns options for container %s: %v", c.RuntimeID, err)
+		return nil
+	}
+
+	return hostConfig.DNSOptions
+}
+
 // GetLogOptions gets the log 'options' map passed into the task definition.
 // see https://docs.aws.amazon.com/AmazonECS/latest/APIReference/API_LogConfiguration.html
 func (c *Container) GetLogOptions() map[string]string {
@@ -1272,6 +1411,80 @@ func (c *Container) GetNetworkModeFromHostConfig() string {
 	return hostConfig.NetworkMode.NetworkName()
 }
 
+// GetLocalHostnames returns the hostnames the container can be resolved by within the
+// task: the hostname set in the container's config and the host names of the extra
+// hosts entries of its host config. It returns nil for containers with default setups.
+func (c *Container) GetLocalHostnames() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var hostnames []string
+	if c.DockerConfig.Config != nil {
+		config := &dockercontainer.Config{}
+		if err := json.Unmarshal([]byte(*c.DockerConfig.Config), config); err == nil && config.Hostname != "" {
+			hostnames = append(hostnames, config.Hostname)
+		}
+	}
+	if c.DockerConfig.HostConfig != nil {
+		hostConfig := &dockercontainer.HostConfig{}
+		if err := json.Unmarshal([]byte(*c.DockerConfig.HostConfig), hostConfig); err == nil {
+			for _, extraHost := range hostConfig.ExtraHosts {
+				// Extra hosts are of the form "hostname:IP"
+				if hostname, _, found := strings.Cut(extraHost, ":"); found && hostname != "" {
+					hostnames = append(hostnames, hostname)
+				}
+			}
+		}
+	}
+	return hostnames
+}
+
+// GetHealthCheckStartPeriod returns the start period of the container's docker health check.
+// It returns zero if no health check or start period is configured.
+func (c *Container) GetHealthCheckStartPeriod() time.Duration {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.DockerConfig.Config == nil {
+		return 0
+	}
+
+	config := &dockercontainer.Config{}
+	if err := json.Unmarshal([]byte(*c.DockerConfig.Config), config); err != nil || config.Healthcheck == nil {
+		return 0
+	}
+	return config.Healthcheck.StartPeriod
+}
+
+// GetContainerTags returns the container level tags carried by the container's docker
+// labels. It returns nil if the container has no tag-bearing labels.
+func (c *Container) GetContainerTags() map[string]string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.DockerConfig.Config == nil {
+		return nil
+	}
+
+	config := &dockercontainer.Config{}
+	if err := json.Unmarshal([]byte(*c.DockerConfig.Config), config); err != nil {
+		return nil
+	}
+
+	var tags map[string]string
+	for label, value := range config.Labels {
+		key := strings.TrimPrefix(label, containerTagLabelPrefix)
+		if key == label || key == "" {
+			continue
+		}
+		if tags == nil {
+			tags = make(map[string]string)
+		}
+		tags[key] = value
+	}
+	return tags
+}
+
 // GetHostConfig returns the container's host config.
 func (c *Container) GetHostConfig() *string {
 	c.lock.RLock()
@@ -1356,6 +1569,12 @@ func (c *Container) UpdateManagedAgentByName(agentName string, state ManagedAgen
 	defer c.lock.Unlock()
 	for i, ma := range c.ManagedAgentsUnsafe {
 		if ma.Name == agentName {
+			if state.LastStatusChange.IsZero() {
+				state.LastStatusChange = ma.LastStatusChange
+				if state.Status != ma.Status {
+					state.LastStatusChange = time.Now()
+				}
+			}
 			// It's necessary to clone the whole ManagedAgent struct
 			c.ManagedAgentsUnsafe[i] = ManagedAgent{
 				Name:              ma.Name,
@@ -1375,6 +1594,9 @@ func (c *Container) UpdateManagedAgentStatus(agentName string, status apicontain
 	defer c.lock.Unlock()
 	for i, ma := range c.ManagedAgentsUnsafe {
 		if ma.Name == agentName {
+			if ma.Status != status {
+				c.ManagedAgentsUnsafe[i].LastStatusChange = time.Now()
+			}
 			c.ManagedAgentsUnsafe[i].Status = status
 			return true
 		}
@@ -1460,6 +1682,39 @@ func (c *Container) getCredentialSpecFromHostConfig() (string, error) {
 	return "", errors.New("unable to obtain credentialspec")
 }
 
+// GetSELinuxLabels returns the SELinux label options resolved in the container's hostConfig
+// security options. It returns nil if the container uses default labeling.
+func (c *Container) GetSELinuxLabels() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.DockerConfig.HostConfig == nil {
+		return nil
+	}
+
+	hostConfig := &dockercontainer.HostConfig{}
+	if err := json.Unmarshal([]byte(*c.DockerConfig.HostConfig), hostConfig); err != nil {
+		return nil
+	}
+
+	var labels []string
+	for _, opt := range hostConfig.SecurityOpt {
+		if strings.HasPrefix(opt, seLinuxLabelPrefix+"=") || strings.HasPrefix(opt, seLinuxLabelPrefix+":") {
+			labels = append(labels, opt[len(seLinuxLabelPrefix)+1:])
+		}
+	}
+	return labels
+}
+
+// GetWebIdentityTokenPath returns the path of the web identity token file configured in the
+// container's environment. It returns an empty string if web identity isn't configured.
+func (c *Container) GetWebIdentityTokenPath() string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.Environment[webIdentityTokenFileEnvVarName]
+}
+
 func (c *Container) getCredentialSpecFromCredentialSpecsContainerField() (string, error) {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
@@ -1513,6 +1768,20 @@ func (c *Container) IsContainerTornDown() bool {
 	return c.ContainerTornDownUnsafe
 }
 
+// SetStopRequested records whether the agent has asked the runtime to stop the container
+func (c *Container) SetStopRequested(stopRequested bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.StopRequestedUnsafe = stopRequested
+}
+
+// IsStopRequested returns whether the agent has asked the runtime to stop the container
+func (c *Container) IsStopRequested() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.StopRequestedUnsafe
+}
+
 func (c *Container) SetContainerHasPortRange(containerHasPortRange bool) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -1569,6 +1838,23 @@ func (c *Container) DigestResolved() bool {
 	return !c.IsInternal() && c.GetImageDigest() != "" && !referenceutil.DigestExists(c.Image)
 }
 
+// SetLastStats sets the most recent docker stats sample collected for the container.
+func (c *Container) SetLastStats(stats *types.StatsJSON) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.lastStats = stats
+}
+
+// GetLastStats gets the most recent docker stats sample collected for the container, it's nil
+// if no stats were collected.
+func (c *Container) GetLastStats() *types.StatsJSON {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.lastStats
+}
+
 // GetRestartAggregationDataForStats gets the restart aggregation data for stats of a container.
 func (c *Container) GetRestartAggregationDataForStats() ContainerRestartAggregationDataForStats {
 	c.lock.RLock()
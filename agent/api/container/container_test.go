@@ -718,6 +718,44 @@ func TestGetLogDriver(t *testing.T) {
 	}
 }
 
+func TestGetDNSOptions(t *testing.T) {
+	getContainer := func(hostConfig string) *Container {
+		c := &Container{
+			Name: "c",
+		}
+		c.DockerConfig.HostConfig = &hostConfig
+		return c
+	}
+
+	testCases := []struct {
+		name       string
+		container  *Container
+		dnsOptions []string
+	}{
+		{
+			name:       "positive case",
+			container:  getContainer(`{"DnsOptions":["ndots:2","timeout:1"]}`),
+			dnsOptions: []string{"ndots:2", "timeout:1"},
+		},
+		{
+			name:       "no host config",
+			container:  &Container{Name: "c"},
+			dnsOptions: nil,
+		},
+		{
+			name:       "negative case",
+			container:  getContainer("invalid"),
+			dnsOptions: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.dnsOptions, tc.container.GetDNSOptions())
+		})
+	}
+}
+
 func TestGetNetworkModeFromHostConfig(t *testing.T) {
 	getContainer := func(hostConfig string) *Container {
 		c := &Container{
@@ -966,11 +1004,48 @@ func TestUpdateManagedAgentByName(t *testing.T) {
 			}
 			agent, ok = c.GetManagedAgentByName(test.agentName)
 			assert.Equal(t, expectAgentFound, ok)
+			if expectAgentFound {
+				// the status changed, so the time of the change is recorded
+				assert.False(t, agent.LastStatusChange.IsZero())
+				agent.LastStatusChange = time.Time{}
+			}
 			assert.Equal(t, newState, agent.ManagedAgentState)
 		})
 	}
 }
 
+func TestUpdateManagedAgentLastStatusChange(t *testing.T) {
+	const dummyAgent = "dummyAgent"
+	lastStatusChange := time.Now().Add(-time.Hour)
+	c := &Container{
+		ManagedAgentsUnsafe: []ManagedAgent{
+			{
+				Name: dummyAgent,
+				ManagedAgentState: ManagedAgentState{
+					Status:           apicontainerstatus.ManagedAgentRunning,
+					LastStatusChange: lastStatusChange,
+				},
+			},
+		},
+	}
+
+	// Updating the state without changing the status keeps the time of the last change.
+	c.UpdateManagedAgentByName(dummyAgent, ManagedAgentState{
+		Status: apicontainerstatus.ManagedAgentRunning,
+		Reason: "reason",
+	})
+	agent, _ := c.GetManagedAgentByName(dummyAgent)
+	assert.Equal(t, lastStatusChange, agent.LastStatusChange)
+	c.UpdateManagedAgentStatus(dummyAgent, apicontainerstatus.ManagedAgentRunning)
+	agent, _ = c.GetManagedAgentByName(dummyAgent)
+	assert.Equal(t, lastStatusChange, agent.LastStatusChange)
+
+	// Changing the status records the time of the change.
+	c.UpdateManagedAgentStatus(dummyAgent, apicontainerstatus.ManagedAgentStopped)
+	agent, _ = c.GetManagedAgentByName(dummyAgent)
+	assert.True(t, agent.LastStatusChange.After(lastStatusChange))
+}
+
 func TestUpdateManagedAgentSentStatus(t *testing.T) {
 	const dummyAgent = "dummyAgent"
 	cases := []struct {
@@ -1217,6 +1292,57 @@ func TestRequiresDomainlessCredentialSpec(t *testing.T) {
 	}
 }
 
+func TestGetSELinuxLabels(t *testing.T) {
+	testCases := []struct {
+		name           string
+		container      *Container
+		expectedOutput []string
+	}{
+		{
+			name:           "hostconfig_nil",
+			container:      &Container{},
+			expectedOutput: nil,
+		},
+		{
+			name:           "invalid_case",
+			container:      getContainer("invalid", nil),
+			expectedOutput: nil,
+		},
+		{
+			name:           "default_labeling",
+			container:      getContainer("{\"SecurityOpt\": [\"no-new-privileges\"]}", nil),
+			expectedOutput: nil,
+		},
+		{
+			name: "label_options",
+			container: getContainer(
+				"{\"SecurityOpt\": [\"label=level:s0:c100,c200\", \"no-new-privileges\", \"label:type:svirt_apache_t\"]}",
+				nil),
+			expectedOutput: []string{"level:s0:c100,c200", "type:svirt_apache_t"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedOutput, tc.container.GetSELinuxLabels())
+		})
+	}
+}
+
+func TestGetLocalHostnames(t *testing.T) {
+	assert.Nil(t, (&Container{}).GetLocalHostnames())
+
+	config := "{\"Hostname\": \"app\"}"
+	hostConfig := "{\"ExtraHosts\": [\"db:10.0.0.2\", \"cache:10.0.0.3\"]}"
+	cont := &Container{
+		DockerConfig: DockerConfig{
+			Config:     &config,
+			HostConfig: &hostConfig,
+		},
+	}
+	assert.Equal(t, []string{"app", "db", "cache"}, cont.GetLocalHostnames())
+}
+
 func TestGetCredentialSpecErr(t *testing.T) {
 	testCases := []struct {
 		name                 string
@@ -1474,3 +1600,62 @@ func TestDigestResolved(t *testing.T) {
 		assert.False(t, (&Container{Image: image, ImageDigest: imageDigest}).DigestResolved())
 	})
 }
+
+func TestGetWebIdentityTokenPath(t *testing.T) {
+	container := &Container{}
+	assert.Empty(t, container.GetWebIdentityTokenPath())
+
+	container.MergeEnvironmentVariables(map[string]string{
+		"AWS_ROLE_ARN":                "arn:aws:iam::123456789012:role/app",
+		"AWS_WEB_IDENTITY_TOKEN_FILE": "/var/run/secrets/token",
+	})
+	assert.Equal(t, "/var/run/secrets/token", container.GetWebIdentityTokenPath())
+}
+
+func TestGetContainerTags(t *testing.T) {
+	assert.Nil(t, (&Container{}).GetContainerTags())
+
+	for _, config := range []string{"invalid", "{\"Labels\": {\"app\": \"web\"}}"} {
+		cont := &Container{DockerConfig: DockerConfig{Config: &config}}
+		assert.Nil(t, cont.GetContainerTags())
+	}
+
+	config := "{\"Labels\": {\"app\": \"web\", \"com.amazonaws.ecs.tag.team\": \"payments\", \"com.amazonaws.ecs.tag.\": \"empty\"}}"
+	cont := &Container{DockerConfig: DockerConfig{Config: &config}}
+	assert.Equal(t, map[string]string{"team": "payments"}, cont.GetContainerTags())
+}
+
+func TestGetHealthCheckStartPeriod(t *testing.T) {
+	assert.Zero(t, (&Container{}).GetHealthCheckStartPeriod())
+
+	for _, config := range []string{"invalid", "{\"Hostname\": \"app\"}"} {
+		cont := &Container{DockerConfig: DockerConfig{Config: &config}}
+		assert.Zero(t, cont.GetHealthCheckStartPeriod())
+	}
+
+	config := "{\"Healthcheck\": {\"Test\": [\"CMD\", \"true\"], \"StartPeriod\": 30000000000}}"
+	cont := &Container{DockerConfig: DockerConfig{Config: &config}}
+	assert.Equal(t, 30*time.Second, cont.GetHealthCheckStartPeriod())
+}
+
+func TestSetPulledImageDigest(t *testing.T) {
+	c := &Container{}
+	c.SetPulledImageDigest("sha256:a")
+	assert.Equal(t, "sha256:a", c.GetImageDigest())
+	assert.Empty(t, c.GetExpectedImageDigest())
+
+	c.SetPulledImageDigest("sha256:a")
+	assert.Empty(t, c.GetExpectedImageDigest())
+
+	c.SetPulledImageDigest("")
+	assert.Equal(t, "sha256:a", c.GetImageDigest())
+	assert.Empty(t, c.GetExpectedImageDigest())
+
+	c.SetPulledImageDigest("sha256:b")
+	assert.Equal(t, "sha256:b", c.GetImageDigest())
+	assert.Equal(t, "sha256:a", c.GetExpectedImageDigest())
+
+	c.ClearExpectedImageDigest()
+	assert.Equal(t, "sha256:b", c.GetImageDigest())
+	assert.Empty(t, c.GetExpectedImageDigest())
+}
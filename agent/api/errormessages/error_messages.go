@@ -61,6 +61,7 @@ const (
 	MissingECRBatchGetImageError DockerErrorType = iota
 	ECRImageDoesNotExistError
 	NetworkConfigurationError
+	ImagePullRateLimitError
 )
 
 type DockerError struct {
@@ -76,6 +77,7 @@ var errorMessageFunctions = map[DockerErrorType]ErrorMessageFunc{
 	MissingECRBatchGetImageError: formatMissingECRBatchGetImageError,
 	ECRImageDoesNotExistError:    formatImageDoesNotExistError,
 	NetworkConfigurationError:    formatNetworkConfigurationError,
+	ImagePullRateLimitError:      formatImagePullRateLimitError,
 }
 
 // A map associating DockerErrorType with parser functions.
@@ -83,6 +85,7 @@ var errorParsers = map[DockerErrorType]func(string) (DockerError, error){
 	MissingECRBatchGetImageError: parseMissingPullImagePermsError,
 	ECRImageDoesNotExistError:    parseImageDoesNotExistError,
 	NetworkConfigurationError:    parseNetworkConfigurationError,
+	ImagePullRateLimitError:      parseImagePullRateLimitError,
 }
 
 // An interface that provides means to reconstruct Named Errors. This is
@@ -97,6 +100,7 @@ const (
 	PatternECRBatchGetImageError     = `denied: User: (.+) is not authorized to perform: ecr:BatchGetImage on resource`
 	PatternImageDoesNotExistError    = `denied: requested access to the resource is denied`
 	PatternNetworkConfigurationError = `request canceled while waiting for connection`
+	PatternImagePullRateLimitError   = `toomanyrequests|pull rate limit`
 	// internal errors
 	ErrorMessageDoesNotMatch = `ErrorMessageDoesNotMatch`
 )
@@ -147,6 +151,28 @@ func parseNetworkConfigurationError(err string) (DockerError, error) {
 	return DockerError{RawError: err}, errors.New(ErrorMessageDoesNotMatch)
 }
 
+// Parses image pull errors caused by the registry's pull rate limit. Example message:
+//
+//	Error response from daemon: toomanyrequests: You have reached your pull rate limit.
+//	You may increase the limit by authenticating and upgrading: https://www.docker.com/increase-rate-limit
+func parseImagePullRateLimitError(err string) (DockerError, error) {
+	matched, _ := regexp.MatchString(PatternImagePullRateLimitError, err)
+	if matched {
+		return DockerError{
+			Type:     ImagePullRateLimitError,
+			RawError: err,
+		}, nil
+	}
+	return DockerError{RawError: err}, errors.New(ErrorMessageDoesNotMatch)
+}
+
+// IsImagePullRateLimitError checks whether the error message is of an image pull that
+// failed because the registry's pull rate limit was reached.
+func IsImagePullRateLimitError(errMsg string) bool {
+	_, err := parseImagePullRateLimitError(errMsg)
+	return err == nil
+}
+
 // Extend error with extra useful information. Works with NamedErrors.
 func AugmentNamedErrMsg(namedErr apierrors.NamedError) apierrors.NamedError {
 	augmentableErr, ok := namedErr.(AugmentableNamedError)
@@ -230,3 +256,14 @@ func formatNetworkConfigurationError(errorData DockerError) string {
 
 	return formattedMessage
 }
+
+// Generates error message for image pull rate limit errors.
+func formatImagePullRateLimitError(errorData DockerError) string {
+	rawError := errorData.RawError
+
+	formattedMessage := fmt.Sprintf(
+		"The task can’t pull the image. The registry's pull rate limit was reached. %s",
+		rawError)
+
+	return formattedMessage
+}
@@ -48,6 +48,11 @@ func TestAugmentMessage(t *testing.T) {
 			errMsg:      "RequestError: send request failed\ncaused by: Post \"https://api.ecr.us-east-1.amazonaws.com/\": net/http: request canceled while waiting for connection (Client.Timeout exceeded while awaiting headers)",
 			expectedMsg: "The task can’t pull the image. Check your network configuration. RequestError: send request failed\ncaused by: Post \"https://api.ecr.us-east-1.amazonaws.com/\": net/http: request canceled while waiting for connection (Client.Timeout exceeded while awaiting headers)",
 		},
+		{
+			testName:    "Successful augmentation - pull rate limit",
+			errMsg:      "Error response from daemon: toomanyrequests: You have reached your pull rate limit. You may increase the limit by authenticating and upgrading: https://www.docker.com/increase-rate-limit",
+			expectedMsg: "The task can’t pull the image. The registry's pull rate limit was reached. Error response from daemon: toomanyrequests: You have reached your pull rate limit. You may increase the limit by authenticating and upgrading: https://www.docker.com/increase-rate-limit",
+		},
 		{
 			testName:    "Does not recognize unknown error",
 			errMsg:      "API error (500): Get https://registry-1.docker.io/v2/library/amazonlinux/manifests/1: unauthorized: incorrect username or password",
@@ -68,6 +73,13 @@ func TestAugmentMessage(t *testing.T) {
 	}
 }
 
+func TestIsImagePullRateLimitError(t *testing.T) {
+	require.True(t, IsImagePullRateLimitError("Error response from daemon: toomanyrequests: Too Many Requests."))
+	require.True(t, IsImagePullRateLimitError("You have reached your pull rate limit."))
+	require.False(t, IsImagePullRateLimitError("denied: requested access to the resource is denied"))
+	require.False(t, IsImagePullRateLimitError(""))
+}
+
 type testCaseAugmentNamedErrMsg struct {
 	testName    string
 	errMsg      apierrors.NamedError
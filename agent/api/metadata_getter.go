@@ -18,6 +18,7 @@ import (
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 )
 
 // Implementation of the ContainerStateChange ContainerMetadataGetter Interface.
@@ -53,6 +54,62 @@ func (cmg *containerMetadataGetter) GetContainerIsEssential() bool {
 	return cmg.container.IsEssential()
 }
 
+// GetContainerName returns the name of the container.
+func (cmg *containerMetadataGetter) GetContainerName() string {
+	return cmg.container.Name
+}
+
+// GetContainerImageDigest returns the digest of the container image.
+func (cmg *containerMetadataGetter) GetContainerImageDigest() string {
+	return cmg.container.GetImageDigest()
+}
+
+// GetContainerImageName returns the image of the container, as specified in the task
+// definition, including its tag.
+func (cmg *containerMetadataGetter) GetContainerImageName() string {
+	return cmg.container.Image
+}
+
+// GetContainerExitCode returns the known exit code of the container.
+func (cmg *containerMetadataGetter) GetContainerExitCode() *int {
+	return cmg.container.GetKnownExitCode()
+}
+
+// GetContainerHealthStatus returns the docker health check status of the container, or
+// unknown if the container has no docker health check.
+func (cmg *containerMetadataGetter) GetContainerHealthStatus() apicontainerstatus.ContainerHealthStatus {
+	if !cmg.container.HealthStatusShouldBeReported() {
+		return apicontainerstatus.ContainerHealthUnknown
+	}
+	return cmg.container.GetHealthStatus().Status
+}
+
+// GetContainerNetworkMode returns the network mode the container was started in.
+func (cmg *containerMetadataGetter) GetContainerNetworkMode() string {
+	return cmg.container.GetNetworkMode()
+}
+
+// GetContainerCreatedAt returns the time the container was created at.
+func (cmg *containerMetadataGetter) GetContainerCreatedAt() time.Time {
+	return cmg.container.GetCreatedAt()
+}
+
+// GetContainerStartedAt returns the time the container was started at. It is zero if the
+// container never started.
+func (cmg *containerMetadataGetter) GetContainerStartedAt() time.Time {
+	return cmg.container.GetStartedAt()
+}
+
+// GetContainerStoppedAt returns the time the container stopped at.
+func (cmg *containerMetadataGetter) GetContainerStoppedAt() time.Time {
+	return cmg.container.GetFinishedAt()
+}
+
+// GetContainerAssignedDevices returns the IDs of the GPUs assigned to the container.
+func (cmg *containerMetadataGetter) GetContainerAssignedDevices() []string {
+	return cmg.container.GPUIDs
+}
+
 // Implementation of the TaskStateChange TaskMetadataGetter Interface.
 type taskMetadataGetter struct {
 	task *apitask.Task
@@ -69,6 +126,16 @@ func (tmg *taskMetadataGetter) GetTaskIsNil() bool {
 	return tmg.task == nil
 }
 
+// GetTaskArn returns the ARN of the task.
+func (tmg *taskMetadataGetter) GetTaskArn() string {
+	return tmg.task.Arn
+}
+
+// GetTaskKnownStatus returns the KnownStatus of the task.
+func (tmg *taskMetadataGetter) GetTaskKnownStatus() string {
+	return tmg.task.GetKnownStatus().String()
+}
+
 // GetTaskSentStatusString returns the SentStatus of the task.
 func (tmg *taskMetadataGetter) GetTaskSentStatusString() string {
 	return tmg.task.GetSentStatus().String()
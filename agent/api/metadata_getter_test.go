@@ -36,6 +36,7 @@ func TestTaskStateChangeMetadataGetter(t *testing.T) {
 	t3 := t2.Add(1)
 	task := &apitask.Task{
 		Arn:                      taskArn,
+		KnownStatusUnsafe:        apitaskstatus.TaskStopped,
 		SentStatusUnsafe:         apitaskstatus.TaskRunning,
 		PullStartedAtUnsafe:      t1,
 		PullStoppedAtUnsafe:      t2,
@@ -50,6 +51,8 @@ func TestTaskStateChangeMetadataGetter(t *testing.T) {
 
 	assert.NotNil(t, change.MetadataGetter)
 	assert.Equal(t, false, change.MetadataGetter.GetTaskIsNil())
+	assert.Equal(t, taskArn, change.MetadataGetter.GetTaskArn())
+	assert.Equal(t, apitaskstatus.TaskStopped.String(), change.MetadataGetter.GetTaskKnownStatus())
 	assert.Equal(t, apitaskstatus.TaskRunningString, change.MetadataGetter.GetTaskSentStatusString())
 	assert.Equal(t, t1, change.MetadataGetter.GetTaskPullStartedAt())
 	assert.Equal(t, t2, change.MetadataGetter.GetTaskPullStoppedAt())
@@ -61,10 +64,16 @@ func TestTaskStateChangeMetadataGetter(t *testing.T) {
 // fetch this data from the container reference, not the state change itself.
 func TestContainerStateChangeMetadataGetter(t *testing.T) {
 	dockerID := "dockerID"
+	exitCode := 1
 	container := &apicontainer.Container{
-		RuntimeID:        dockerID,
-		Essential:        true,
-		SentStatusUnsafe: apicontainerstatus.ContainerRunning,
+		Name:                "c1",
+		RuntimeID:           dockerID,
+		Essential:           true,
+		SentStatusUnsafe:    apicontainerstatus.ContainerRunning,
+		Image:               "myrepo/app:1.2",
+		ImageDigest:         "sha256:abc",
+		KnownExitCodeUnsafe: &exitCode,
+		GPUIDs:              []string{"GPU-0"},
 	}
 
 	metadataGetter := newContainerMetadataGetter(container)
@@ -78,4 +87,9 @@ func TestContainerStateChangeMetadataGetter(t *testing.T) {
 	assert.Equal(t, apicontainerstatus.ContainerRunning.String(), change.MetadataGetter.GetContainerSentStatusString())
 	assert.Equal(t, dockerID, change.MetadataGetter.GetContainerRuntimeID())
 	assert.Equal(t, true, change.MetadataGetter.GetContainerIsEssential())
+	assert.Equal(t, "c1", change.MetadataGetter.GetContainerName())
+	assert.Equal(t, "myrepo/app:1.2", change.MetadataGetter.GetContainerImageName())
+	assert.Equal(t, "sha256:abc", change.MetadataGetter.GetContainerImageDigest())
+	assert.Equal(t, &exitCode, change.MetadataGetter.GetContainerExitCode())
+	assert.Equal(t, []string{"GPU-0"}, change.MetadataGetter.GetContainerAssignedDevices())
 }
@@ -16,11 +16,14 @@ package api
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/api/errormessages"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
+	"github.com/aws/amazon-ecs-agent/agent/utils/reference"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
@@ -32,7 +35,9 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/docker/docker/api/types"
 	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
 	"github.com/pkg/errors"
 )
 
@@ -40,6 +45,17 @@ const (
 	// ecsMaxNetworkBindingsLength is the maximum length of the ecs.NetworkBindings list sent as part of the
 	// container state change payload. Currently, this is enforced only when containerPortRanges are requested.
 	ecsMaxNetworkBindingsLength = 100
+
+	// logModeOption and logMaxBufferSizeOption are the docker log options that configure how
+	// log messages are delivered to the log driver
+	logModeOption          = "mode"
+	logMaxBufferSizeOption = "max-buffer-size"
+	// logModeBlocking is the docker default log delivery mode
+	logModeBlocking = "blocking"
+
+	// ImageDigestChangedReasonPrefix prefixes the reason of a container state change when the
+	// digest of the pulled image differs from the digest resolved for the container
+	ImageDigestChangedReasonPrefix = "ImageDigestChanged:"
 )
 
 // ContainerStateChange represents a state change that needs to be sent to the
@@ -47,6 +63,8 @@ const (
 type ContainerStateChange struct {
 	// TaskArn is the unique identifier for the task
 	TaskArn string
+	// EventID is the client generated UUID identifying the state change
+	EventID string
 	// RuntimeID is the dockerID of the container
 	RuntimeID string
 	// ContainerName is the name of the container
@@ -57,11 +75,22 @@ type ContainerStateChange struct {
 	ImageDigest string
 	// Reason may contain details of why the container stopped
 	Reason string
+	// ReasonCode is the name of the error the reason was derived from, if any
+	ReasonCode string
 	// ExitCode is the exit code of the container, if available
 	ExitCode *int
 	// PortBindings are the details of the host ports picked for the specified
 	// container ports
 	PortBindings []apicontainer.PortBinding
+	// Reconciled indicates that the state change was generated while reconciling
+	// state after an agent restart rather than by a live transition
+	Reconciled bool
+	// AgentInjected indicates that the container was injected into the task by the agent,
+	// such as the Service Connect proxy container
+	AgentInjected bool
+	// ImagePullRateLimited indicates that pulling the container's image failed because the
+	// registry's pull rate limit was reached
+	ImagePullRateLimited bool
 	// Container is a pointer to the container involved in the state change that gives the event handler a hook into
 	// storing what status was sent.  This is used to ensure the same event is handled only once.
 	Container *apicontainer.Container
@@ -79,6 +108,8 @@ type ManagedAgentStateChange struct {
 	Status apicontainerstatus.ManagedAgentStatus
 	// Reason indicates an error in a managed agent state chage
 	Reason string
+	// LastStatusChange is the time at which the managed agent last changed status
+	LastStatusChange time.Time
 }
 
 // TaskStateChange represents a state change that needs to be sent to the
@@ -88,10 +119,14 @@ type TaskStateChange struct {
 	Attachment *ni.ENIAttachment
 	// TaskArn is the unique identifier for the task
 	TaskARN string
+	// EventID is the client generated UUID identifying the state change
+	EventID string
 	// Status is the status to send
 	Status apitaskstatus.TaskStatus
 	// Reason may contain details of why the task stopped
 	Reason string
+	// ReasonCode is the name of the error that stopped the task's essential container, if any
+	ReasonCode string
 	// Containers holds the events generated by containers owned by this task
 	Containers []ContainerStateChange
 	// ManagedAgents contain the name and status of Agents running inside the container
@@ -102,6 +137,15 @@ type TaskStateChange struct {
 	PullStoppedAt *time.Time
 	// ExecutionStoppedAt is the timestamp when the essential container stopped
 	ExecutionStoppedAt *time.Time
+	// Reconciled indicates that the state change was generated while reconciling
+	// state after an agent restart rather than by a live transition
+	Reconciled bool
+	// AgentResourcePressure is a snapshot of the agent's resource usage taken when a
+	// STOPPED change is submitted
+	AgentResourcePressure *ecs.AgentResourcePressure
+	// RuntimePlatformVersion is the version of the runtime platform the task runs on, as
+	// configured for the agent
+	RuntimePlatformVersion string
 	// Task is a pointer to the task involved in the state change that gives the event handler a hook into storing
 	// what status was sent.  This is used to ensure the same event is handled only once.
 	Task *apitask.Task
@@ -112,6 +156,8 @@ type TaskStateChange struct {
 type AttachmentStateChange struct {
 	// Attachment is the attachment object to send
 	Attachment attachment.Attachment
+	// EventID is the client generated UUID identifying the state change
+	EventID string
 }
 
 type ErrShouldNotSendEvent struct {
@@ -149,18 +195,56 @@ func NewTaskStateChangeEvent(task *apitask.Task, reason string) (TaskStateChange
 		}
 	}
 
+	if reason == "" && taskKnownStatus == apitaskstatus.TaskStopped {
+		reason = getImagePullRateLimitedReason(task)
+	}
+
 	event = TaskStateChange{
 		TaskARN: task.Arn,
+		EventID: ecs.NewEventID(),
 		Status:  taskKnownStatus,
-		Reason:  reason,
+		Reason:  ecs.TruncateReason(reason, ecs.DefaultMaxReasonLength),
 		Task:    task,
 	}
+	if taskKnownStatus == apitaskstatus.TaskStopped {
+		event.ReasonCode = getEssentialContainerReasonCode(task)
+	}
 
 	event.SetTaskTimestamps()
 
 	return event, nil
 }
 
+// getImagePullRateLimitedReason returns the error of the first essential container of the
+// task whose image pull failed because the registry's pull rate limit was reached, or an
+// empty string if there is no such container.
+func getImagePullRateLimitedReason(task *apitask.Task) string {
+	for _, cont := range task.Containers {
+		if !cont.IsEssential() || cont.ApplyingError == nil {
+			continue
+		}
+		if errMsg := cont.ApplyingError.Error(); errormessages.IsImagePullRateLimitError(errMsg) {
+			return errMsg
+		}
+	}
+	return ""
+}
+
+// getEssentialContainerReasonCode returns the reason code of the error of the first
+// essential container of the task that failed to transition, or an empty string if there
+// is no such container.
+func getEssentialContainerReasonCode(task *apitask.Task) string {
+	for _, cont := range task.Containers {
+		if !cont.IsEssential() || cont.ApplyingError == nil {
+			continue
+		}
+		if reasonCode := ecs.ReasonCodeFromError(cont.ApplyingError); reasonCode != "" {
+			return reasonCode
+		}
+	}
+	return ""
+}
+
 // NewContainerStateChangeEvent creates a new container state change event
 // returns error if the state change doesn't need to be sent to the ECS backend.
 func NewContainerStateChangeEvent(task *apitask.Task, cont *apicontainer.Container, reason string) (ContainerStateChange, error) {
@@ -190,8 +274,14 @@ func NewContainerStateChangeEvent(task *apitask.Task, cont *apicontainer.Contain
 			contKnownStatus.String(), cont.Name, task.Arn)}
 	}
 	if reason == "" && cont.ApplyingError != nil {
-		reason = cont.ApplyingError.Error()
-		event.Reason = reason
+		event.Reason = ecs.TruncateReason(cont.ApplyingError.Error(), ecs.DefaultMaxReasonLength)
+		event.ReasonCode = ecs.ReasonCodeFromError(cont.ApplyingError)
+	}
+	// A change of the image digest is reported until a state change of the container has
+	// been sent, at which point the expected image digest is cleared.
+	if expectedDigest := cont.GetExpectedImageDigest(); expectedDigest != "" {
+		event.Reason = ecs.AppendReason(event.Reason, fmt.Sprintf("%s expected %s, pulled %s",
+			ImageDigestChangedReasonPrefix, expectedDigest, event.ImageDigest))
 	}
 	return event, nil
 }
@@ -212,18 +302,33 @@ func newUncheckedContainerStateChangeEvent(task *apitask.Task, cont *apicontaine
 	contKnownStatus := cont.GetKnownStatus()
 	event = ContainerStateChange{
 		TaskArn:       task.Arn,
+		EventID:       ecs.NewEventID(),
 		ContainerName: cont.Name,
 		RuntimeID:     cont.GetRuntimeID(),
 		Status:        containerStatusChangeStatus(contKnownStatus, cont.GetSteadyStateStatus()),
 		ExitCode:      cont.GetKnownExitCode(),
 		PortBindings:  portBindings,
 		ImageDigest:   cont.GetImageDigest(),
-		Reason:        reason,
+		Reason:        ecs.TruncateReason(reason, ecs.DefaultMaxReasonLength),
+		AgentInjected: isAgentInjectedContainer(task, cont),
 		Container:     cont,
 	}
+	if cont.ApplyingError != nil {
+		event.ImagePullRateLimited = errormessages.IsImagePullRateLimitError(cont.ApplyingError.Error())
+	}
 	return event, nil
 }
 
+// isAgentInjectedContainer checks whether the container was injected into the task by
+// the agent rather than defined by the user, i.e. it's an internal container such as a
+// pause container, or the Service Connect proxy container.
+func isAgentInjectedContainer(task *apitask.Task, cont *apicontainer.Container) bool {
+	if cont.IsInternal() {
+		return true
+	}
+	return task.GetServiceConnectContainer() == cont
+}
+
 // Maps container known status to a suitable status for ContainerStateChange.
 //
 // Returns ContainerRunning if known status matches steady state status,
@@ -257,37 +362,47 @@ func NewManagedAgentChangeEvent(task *apitask.Task, cont *apicontainer.Container
 		return event, errors.Errorf("create managed agent state change event: status not recognized by ECS: %v", managedAgent.Status)
 	}
 
+	if reason == "" {
+		reason = managedAgent.Reason
+	}
+
 	event = ManagedAgentStateChange{
-		TaskArn:   task.Arn,
-		Name:      managedAgent.Name,
-		Container: cont,
-		Status:    managedAgent.Status,
-		Reason:    reason,
+		TaskArn:          task.Arn,
+		Name:             managedAgent.Name,
+		Container:        cont,
+		Status:           managedAgent.Status,
+		Reason:           reason,
+		LastStatusChange: managedAgent.LastStatusChange,
 	}
 
 	return event, nil
 }
 
-// NewAttachmentStateChangeEvent creates a new attachment state change event
-func NewAttachmentStateChangeEvent(eniAttachment *ni.ENIAttachment) AttachmentStateChange {
+// NewAttachmentStateChangeEvent creates a new attachment state change event for any kind
+// of attachment, e.g. an ENI or a resource attachment
+func NewAttachmentStateChangeEvent(att attachment.Attachment) AttachmentStateChange {
 	return AttachmentStateChange{
-		Attachment: eniAttachment,
+		Attachment: att,
+		EventID:    ecs.NewEventID(),
 	}
 }
 
 func (c *ContainerStateChange) ToFields() logger.Fields {
 	return logger.Fields{
 		"eventType":       "ContainerStateChange",
+		"eventID":         c.EventID,
 		"taskArn":         c.TaskArn,
 		"containerName":   c.ContainerName,
 		"containerStatus": c.Status.String(),
 		"exitCode":        strconv.Itoa(*c.ExitCode),
-		"reason":          c.Reason,
+		"reason":          ecs.RedactSensitiveData(c.Reason),
+		"reasonCode":      c.ReasonCode,
 		"portBindings":    c.PortBindings,
 	}
 }
 
-// String returns a human readable string representation of this object
+// String returns a human readable string representation of this object, with sensitive
+// data such as credentials leaked into the reason redacted
 func (c *ContainerStateChange) String() string {
 	res := fmt.Sprintf("containerName=%s containerStatus=%s", c.ContainerName, c.Status.String())
 	if c.ExitCode != nil {
@@ -296,14 +411,20 @@ func (c *ContainerStateChange) String() string {
 	if c.Reason != "" {
 		res += " containerReason=" + c.Reason
 	}
+	if c.ReasonCode != "" {
+		res += " reasonCode=" + c.ReasonCode
+	}
 	if len(c.PortBindings) != 0 {
 		res += fmt.Sprintf(" containerPortBindings=%v", c.PortBindings)
 	}
+	if c.EventID != "" {
+		res += " containerEventID=" + c.EventID
+	}
 	if c.Container != nil {
 		res += fmt.Sprintf(" containerKnownSentStatus=%s containerRuntimeID=%s containerIsEssential=%v",
 			c.Container.GetSentStatus().String(), c.Container.GetRuntimeID(), c.Container.IsEssential())
 	}
-	return res
+	return ecs.RedactSensitiveData(res)
 }
 
 // ToECSAgent converts the agent module level ContainerStateChange to ecs-agent module level ContainerStateChange.
@@ -320,17 +441,234 @@ func (c *ContainerStateChange) ToECSAgent() (*ecs.ContainerStateChange, error) {
 		return nil, nil
 	}
 
-	return &ecs.ContainerStateChange{
-		TaskArn:         c.TaskArn,
-		RuntimeID:       aws.StringValue(pl.RuntimeId),
-		ContainerName:   c.ContainerName,
-		Status:          c.Status,
-		ImageDigest:     aws.StringValue(pl.ImageDigest),
-		Reason:          aws.StringValue(pl.Reason),
-		ExitCode:        utils.Int64PtrToIntPtr(pl.ExitCode),
-		NetworkBindings: pl.NetworkBindings,
-		MetadataGetter:  newContainerMetadataGetter(c.Container),
-	}, nil
+	metadataGetter := newContainerMetadataGetter(c.Container)
+	output := &ecs.ContainerStateChange{
+		TaskArn:              c.TaskArn,
+		EventID:              c.EventID,
+		RuntimeID:            aws.StringValue(pl.RuntimeId),
+		ContainerName:        c.ContainerName,
+		Status:               c.Status,
+		ImageDigest:          aws.StringValue(pl.ImageDigest),
+		Reason:               aws.StringValue(pl.Reason),
+		ReasonCode:           c.ReasonCode,
+		ExitCode:             utils.Int64PtrToIntPtr(pl.ExitCode),
+		NetworkBindings:      pl.NetworkBindings,
+		Reconciled:           c.Reconciled,
+		AgentInjected:        c.AgentInjected,
+		ImagePullRateLimited: c.ImagePullRateLimited,
+		MetadataGetter:       metadataGetter,
+	}
+
+	if c.Container != nil {
+		setContainerTimestamps(output, metadataGetter)
+		if devices := metadataGetter.GetContainerAssignedDevices(); len(devices) != 0 {
+			output.AssignedDevices = devices
+		}
+		output.RegistryVisibility = getRegistryVisibility(c.Container.Image)
+		output.CommandArgCount, output.CommandBytes = getCommandStats(c.Container)
+		output.DigestMatchedPinned = getDigestMatchedPinned(c.Container.Image, output.ImageDigest)
+		output.WebIdentityTokenPath = c.Container.GetWebIdentityTokenPath()
+		output.WebIdentityConfigured = output.WebIdentityTokenPath != ""
+		output.ContainerTags = c.Container.GetContainerTags()
+		if c.Container.RestartTracker != nil {
+			output.RestartReasons = c.Container.RestartTracker.GetRecentRestartReasons()
+		}
+	}
+	if c.Container != nil && c.Container.HealthStatusShouldBeReported() {
+		health := c.Container.GetHealthStatus()
+		output.HealthStatus = health.Status
+		if health.Status == apicontainerstatus.ContainerUnhealthy {
+			output.HealthReason = health.Output
+		}
+	}
+	if c.Container != nil && c.Status == apicontainerstatus.ContainerStopped {
+		output.HealthCheckNeverRan = healthCheckNeverRan(c.Container)
+		output.StopMethod = ecs.NewStopMethod(c.Container.IsStopRequested(), output.ExitCode)
+		if stats := c.Container.GetLastStats(); stats != nil {
+			output.CPUThrottling = getCPUThrottlingStats(stats)
+			output.WorkingSetBytes = getWorkingSetBytes(stats)
+			output.AnonymousMemoryBytes, output.PageCacheBytes = getMemoryBreakdown(stats)
+		}
+		if unmetDependency := c.Container.GetUnmetDependency(); unmetDependency != nil {
+			output.DependencyUnmet = &ecs.UnmetDependency{
+				ContainerName: unmetDependency.ContainerName,
+				Condition:     unmetDependency.Condition,
+				Reason:        unmetDependency.Reason,
+			}
+		}
+	}
+	if c.Container != nil && c.Status == apicontainerstatus.ContainerRunning {
+		output.SELinuxLabels = c.Container.GetSELinuxLabels()
+		output.BridgeNetworkName = getBridgeNetworkName(c.Container.GetNetworkMode())
+		output.LocalHostnames = c.Container.GetLocalHostnames()
+		output.LogMode, output.LogBufferSize = getLogModeConfig(c.Container)
+		if imageCreatedAt := c.Container.GetImageCreatedAt(); !imageCreatedAt.IsZero() {
+			output.ImageCreatedAt = aws.Time(imageCreatedAt.UTC())
+		}
+		output.InitPID = c.Container.GetInitPID()
+	}
+
+	return output, nil
+}
+
+// setContainerTimestamps adds the creation, start and stop times of the container, as read
+// through its metadata getter, to the state change. Times the container hasn't reached are
+// left unset.
+func setContainerTimestamps(change *ecs.ContainerStateChange, getter ecs.ContainerMetadataGetter) {
+	if timestamp := getter.GetContainerCreatedAt(); !timestamp.IsZero() {
+		change.CreatedAt = aws.Time(timestamp.UTC())
+	}
+	if timestamp := getter.GetContainerStartedAt(); !timestamp.IsZero() {
+		change.StartedAt = aws.Time(timestamp.UTC())
+	}
+	if timestamp := getter.GetContainerStoppedAt(); !timestamp.IsZero() {
+		change.StoppedAt = aws.Time(timestamp.UTC())
+	}
+}
+
+// getRegistryVisibility classifies the registry an image is pulled from as public or
+// private based on the registry host of the image reference. It returns an empty
+// string if the image reference can't be parsed.
+func getRegistryVisibility(image string) string {
+	host, err := reference.GetRegistryHost(image)
+	if err != nil {
+		return ""
+	}
+	if reference.IsPublicRegistryHost(host) {
+		return ecs.RegistryVisibilityPublic
+	}
+	return ecs.RegistryVisibilityPrivate
+}
+
+// getDigestMatchedPinned checks whether the resolved image digest matches the digest
+// pinned in the image reference of the task definition. It returns nil when the image
+// reference isn't pinned by digest or the resolved digest is unknown.
+func getDigestMatchedPinned(image, imageDigest string) *bool {
+	pinnedDigest := reference.GetDigestFromImageRef(image)
+	if pinnedDigest == "" || imageDigest == "" {
+		return nil
+	}
+	return aws.Bool(pinnedDigest.String() == imageDigest)
+}
+
+// healthCheckNeverRan checks whether a stopped container with a docker health check stopped
+// before its health check start period elapsed, i.e. before its health was ever evaluated.
+func healthCheckNeverRan(cont *apicontainer.Container) bool {
+	if !cont.HealthStatusShouldBeReported() ||
+		cont.GetHealthStatus().Status != apicontainerstatus.ContainerHealthUnknown {
+		return false
+	}
+	startedAt := cont.GetStartedAt()
+	if startedAt.IsZero() {
+		// The container stopped without ever being started
+		return true
+	}
+	finishedAt := cont.GetFinishedAt()
+	if finishedAt.IsZero() {
+		return false
+	}
+	return finishedAt.Sub(startedAt) < cont.GetHealthCheckStartPeriod()
+}
+
+// getCPUThrottlingStats returns the CFS throttling stats of a docker stats sample. It returns
+// nil if the sample has no throttling data, e.g. for containers without a CPU quota.
+func getCPUThrottlingStats(stats *types.StatsJSON) *ecs.CPUThrottlingStats {
+	throttling := stats.CPUStats.ThrottlingData
+	if throttling.Periods == 0 {
+		return nil
+	}
+	return &ecs.CPUThrottlingStats{
+		ThrottledPeriods: throttling.ThrottledPeriods,
+		ThrottledTime:    time.Duration(throttling.ThrottledTime),
+	}
+}
+
+// getWorkingSetBytes returns the working set of a docker stats sample. On Linux it's the memory
+// usage without the inactive page cache, which the kernel can reclaim, and on Windows it's the
+// private working set. It returns zero if the sample has no memory stats.
+func getWorkingSetBytes(stats *types.StatsJSON) int64 {
+	mem := stats.MemoryStats
+	if mem.PrivateWorkingSet != 0 {
+		return int64(mem.PrivateWorkingSet)
+	}
+	// cgroup v2 reports the inactive page cache as inactive_file, cgroup v1 as total_inactive_file
+	inactiveFile, ok := mem.Stats["inactive_file"]
+	if !ok {
+		inactiveFile = mem.Stats["total_inactive_file"]
+	}
+	if inactiveFile >= mem.Usage {
+		return 0
+	}
+	return int64(mem.Usage - inactiveFile)
+}
+
+// getMemoryBreakdown returns the anonymous memory and the page cache of a docker stats sample,
+// as reported by the cgroup memory stats. Both are zero if the cgroup memory stats are
+// unavailable, e.g. on Windows.
+func getMemoryBreakdown(stats *types.StatsJSON) (int64, int64) {
+	mem := stats.MemoryStats.Stats
+	// cgroup v2 reports the anonymous memory as anon and the page cache as file, cgroup v1
+	// reports them hierarchically as total_rss and total_cache
+	anon, ok := mem["anon"]
+	if !ok {
+		anon = mem["total_rss"]
+	}
+	pageCache, ok := mem["file"]
+	if !ok {
+		pageCache = mem["total_cache"]
+	}
+	return int64(anon), int64(pageCache)
+}
+
+// getLogModeConfig returns the delivery mode and the buffer size in bytes of the container's
+// resolved log configuration. Docker defaults to blocking mode when no mode is set. The buffer
+// size is zero if it isn't set or can't be parsed.
+func getLogModeConfig(cont *apicontainer.Container) (string, int) {
+	if cont.GetLogDriver() == "" {
+		return "", 0
+	}
+	logOptions := cont.GetLogOptions()
+	mode := logOptions[logModeOption]
+	if mode == "" {
+		mode = logModeBlocking
+	}
+	bufferSize, err := units.RAMInBytes(logOptions[logMaxBufferSizeOption])
+	if err != nil {
+		return mode, 0
+	}
+	return mode, int(bufferSize)
+}
+
+// getCommandStats returns the number of arguments of the container's resolved command
+// line, i.e. its entrypoint followed by its command, and the number of bytes it takes
+// when passed to exec, including the terminating NUL of each argument. The command line
+// itself isn't returned to avoid leaking secrets passed as arguments.
+func getCommandStats(cont *apicontainer.Container) (int, int) {
+	args := cont.Command
+	if cont.EntryPoint != nil {
+		args = append(append([]string{}, *cont.EntryPoint...), cont.Command...)
+	}
+	commandBytes := 0
+	for _, arg := range args {
+		commandBytes += len(arg) + 1
+	}
+	return len(args), commandBytes
+}
+
+// getBridgeNetworkName returns the name of the bridge network a container with the given
+// docker network mode is attached to. Containers on the default bridge report "bridge",
+// while containers sharing the host's or another container's network namespace report
+// an empty string.
+func getBridgeNetworkName(networkMode string) string {
+	switch {
+	case networkMode == "", networkMode == "host", networkMode == "none",
+		strings.HasPrefix(networkMode, "container:"):
+		return ""
+	case networkMode == "default":
+		return "bridge"
+	default:
+		return networkMode
+	}
 }
 
 // String returns a human readable string representation of ManagedAgentStateChange
@@ -339,6 +677,9 @@ func (m *ManagedAgentStateChange) String() string {
 	if m.Reason != "" {
 		res += " managedAgentReason=" + m.Reason
 	}
+	if !m.LastStatusChange.IsZero() {
+		res += " managedAgentLastStatusChange=" + m.LastStatusChange.UTC().Format(time.RFC3339)
+	}
 	return res
 }
 
@@ -364,9 +705,13 @@ func (change *TaskStateChange) SetTaskTimestamps() {
 func (change *TaskStateChange) ToFields() logger.Fields {
 	fields := logger.Fields{
 		"eventType":  "TaskStateChange",
+		"eventID":    change.EventID,
 		"taskArn":    change.TaskARN,
 		"taskStatus": change.Status.String(),
-		"taskReason": change.Reason,
+		"taskReason": ecs.RedactSensitiveData(change.Reason),
+	}
+	if change.ReasonCode != "" {
+		fields["taskReasonCode"] = change.ReasonCode
 	}
 	if change.Task != nil {
 		fields["taskKnownSentStatus"] = change.Task.GetSentStatus().String()
@@ -386,11 +731,13 @@ func (change *TaskStateChange) ToFields() logger.Fields {
 	return fields
 }
 
-// String returns a human readable string representation of this object
+// String returns a human readable string representation of this object, with sensitive
+// data such as credentials leaked into the reason redacted
 func (change *TaskStateChange) String() string {
 	res := fmt.Sprintf("%s -> %s", change.TaskARN, change.Status.String())
 	if change.Task != nil {
-		res += fmt.Sprintf(", Known Sent: %s, PullStartedAt: %s, PullStoppedAt: %s, ExecutionStoppedAt: %s",
+		res += fmt.Sprintf(", Known: %s, Sent: %s, PullStartedAt: %s, PullStoppedAt: %s, ExecutionStoppedAt: %s",
+			change.Task.GetKnownStatus().String(),
 			change.Task.GetSentStatus().String(),
 			change.Task.GetPullStartedAt(),
 			change.Task.GetPullStoppedAt(),
@@ -399,6 +746,12 @@ func (change *TaskStateChange) String() string {
 	if change.Attachment != nil {
 		res += ", " + change.Attachment.String()
 	}
+	if change.EventID != "" {
+		res += ", EventID: " + change.EventID
+	}
+	if change.ReasonCode != "" {
+		res += ", ReasonCode: " + change.ReasonCode
+	}
 	for _, containerChange := range change.Containers {
 		res += ", container change: " + containerChange.String()
 	}
@@ -406,20 +759,33 @@ func (change *TaskStateChange) String() string {
 		res += ", managed agent: " + managedAgentChange.String()
 	}
 
-	return res
+	return ecs.RedactSensitiveData(res)
 }
 
 // ToECSAgent converts the agent module level TaskStateChange to ecs-agent module level TaskStateChange.
 func (change *TaskStateChange) ToECSAgent() (*ecs.TaskStateChange, error) {
 	output := &ecs.TaskStateChange{
-		Attachment:         change.Attachment,
-		TaskARN:            change.TaskARN,
-		Status:             change.Status,
-		Reason:             change.Reason,
-		PullStartedAt:      change.PullStartedAt,
-		PullStoppedAt:      change.PullStoppedAt,
-		ExecutionStoppedAt: change.ExecutionStoppedAt,
-		MetadataGetter:     newTaskMetadataGetter(change.Task),
+		Attachment:             change.Attachment,
+		TaskARN:                change.TaskARN,
+		EventID:                change.EventID,
+		Status:                 change.Status,
+		Reason:                 change.Reason,
+		ReasonCode:             change.ReasonCode,
+		PullStartedAt:          change.PullStartedAt,
+		PullStoppedAt:          change.PullStoppedAt,
+		ExecutionStoppedAt:     change.ExecutionStoppedAt,
+		Reconciled:             change.Reconciled,
+		AgentResourcePressure:  change.AgentResourcePressure,
+		RuntimePlatformVersion: change.RuntimePlatformVersion,
+		MetadataGetter:         newTaskMetadataGetter(change.Task),
+	}
+
+	if change.Task != nil && change.Task.IsNetworkModeAWSVPC() {
+		output.NetworkConfiguration = ecs.NewTaskNetworkConfiguration(change.Task.GetPrimaryENI())
+		output.PauseContainerStatus = getPauseContainerStatus(change.Task)
+	}
+	if change.Task != nil && change.Status == apitaskstatus.TaskRunning {
+		output.LaunchLatency = newTaskLaunchLatency(change.Task)
 	}
 
 	for _, managedAgentEvent := range change.ManagedAgents {
@@ -427,6 +793,9 @@ func (change *TaskStateChange) ToECSAgent() (*ecs.TaskStateChange, error) {
 			output.ManagedAgents = append(output.ManagedAgents, mgspl)
 		}
 	}
+	// A flapping managed agent may have several changes batched in the task state change,
+	// but the backend expects a single one per agent.
+	output.ManagedAgents = ecs.DedupManagedAgentStateChanges(output.ManagedAgents)
 
 	containerEvents := make([]*ecsmodel.ContainerStateChange, len(change.Containers))
 	for i, containerEvent := range change.Containers {
@@ -445,20 +814,51 @@ func (change *TaskStateChange) ToECSAgent() (*ecs.TaskStateChange, error) {
 	return output, nil
 }
 
+// getPauseContainerStatus returns the known status of the pause container holding the
+// network namespace of an awsvpc task, or nil if the task has no pause container.
+func getPauseContainerStatus(task *apitask.Task) *apicontainerstatus.ContainerStatus {
+	for _, cont := range task.Containers {
+		if cont.Type == apicontainer.ContainerCNIPause {
+			status := cont.GetKnownStatus()
+			return &status
+		}
+	}
+	return nil
+}
+
+// newTaskLaunchLatency computes the launch latency breakdown of a task from the
+// timestamps recorded for the task and its containers.
+func newTaskLaunchLatency(task *apitask.Task) *ecs.TaskLaunchLatency {
+	var lastCreatedAt, lastStartedAt time.Time
+	for _, cont := range task.Containers {
+		if createdAt := cont.GetCreatedAt(); createdAt.After(lastCreatedAt) {
+			lastCreatedAt = createdAt
+		}
+		if startedAt := cont.GetStartedAt(); startedAt.After(lastStartedAt) {
+			lastStartedAt = startedAt
+		}
+	}
+	return ecs.NewTaskLaunchLatency(task.GetPullStartedAt(), task.GetPullStoppedAt(), lastCreatedAt, lastStartedAt)
+}
+
 // String returns a human readable string representation of this object
 func (change *AttachmentStateChange) String() string {
-	if change.Attachment != nil {
-		return fmt.Sprintf("%s -> %v, %s", change.Attachment.GetAttachmentARN(),
-			change.Attachment.GetAttachmentStatus(), change.Attachment.String())
+	if change.Attachment == nil {
+		return ""
 	}
-
-	return ""
+	res := fmt.Sprintf("%s -> %v, %s", change.Attachment.GetAttachmentARN(),
+		change.Attachment.GetAttachmentStatus(), change.Attachment.String())
+	if change.EventID != "" {
+		res += ", EventID: " + change.EventID
+	}
+	return res
 }
 
 // ToECSAgent converts the agent module level AttachmentStateChange to ecs-agent module level AttachmentStateChange.
 func (change *AttachmentStateChange) ToECSAgent() *ecs.AttachmentStateChange {
 	return &ecs.AttachmentStateChange{
 		Attachment: change.Attachment,
+		EventID:    change.EventID,
 	}
 }
 
@@ -538,7 +938,16 @@ func buildContainerStateChangePayload(change ContainerStateChange) (*ecsmodel.Co
 		statechange.ExitCode = aws.Int64(exitCode)
 	}
 
-	networkBindings := getNetworkBindings(change)
+	// The same published port may be reported more than once, e.g. by different observers.
+	networkBindings := ecs.DedupNetworkBindings(getNetworkBindings(change))
+	if change.Container.HasPortRange() {
+		// The requested port ranges are already reported as a single range binding each. For a
+		// container that requested port ranges, the individually requested ports whose container
+		// and host ports are both consecutive are also reported as a single range binding, which
+		// counts towards the limit below as one binding. The ports of other containers are
+		// reported as is.
+		networkBindings = ecs.CollapseNetworkBindingRanges(networkBindings)
+	}
 	// we enforce a limit on the no. of network bindings for containers with at-least 1 port range requested.
 	// this limit is enforced by ECS, and we fail early and don't call SubmitContainerStateChange.
 	if change.Container.HasPortRange() && len(networkBindings) > ecsMaxNetworkBindingsLength {
@@ -214,6 +214,23 @@ func (task *Task) addFSxWindowsFileServerResource(
 	return nil
 }
 
+// getDNSOptions returns the resolver options set by the containers of the task, without
+// duplicates and in the order they first appear in.
+func (task *Task) getDNSOptions() []string {
+	var options []string
+	seen := make(map[string]struct{})
+	for _, container := range task.Containers {
+		for _, option := range container.GetDNSOptions() {
+			if _, ok := seen[option]; ok {
+				continue
+			}
+			seen[option] = struct{}{}
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
 // BuildCNIConfigAwsvpc builds a list of CNI network configurations for the task.
 func (task *Task) BuildCNIConfigAwsvpc(includeIPAMConfig bool, cniConfig *ecscni.Config) (*ecscni.Config, error) {
 	if !task.IsNetworkModeAWSVPC() {
@@ -223,6 +240,7 @@ func (task *Task) BuildCNIConfigAwsvpc(includeIPAMConfig bool, cniConfig *ecscni
 	var netconf *libcni.NetworkConfig
 	var err error
 
+	cniConfig.TaskDNSOptions = task.getDNSOptions()
 	// Build a CNI network configuration for each ENI.
 	for _, eni := range task.ENIs {
 		switch eni.InterfaceAssociationProtocol {
@@ -605,3 +605,44 @@ func TestBuildCNIConfig(t *testing.T) {
 	assert.True(t, eniConfig.UseExistingNetwork)
 	assert.EqualValues(t, ecscni.ECSBridgeNetworkName, cniConfig.NetworkConfigs[1].CNINetworkConfig.Network.Name)
 }
+
+// TestBuildCNIConfigDNS tests that the DNS configuration of the task is passed to the plugin setting up
+// the task ENI.
+func TestBuildCNIConfigDNS(t *testing.T) {
+	hostConfig := `{"DnsOptions":["ndots:2"]}`
+	otherHostConfig := `{"DnsOptions":["ndots:2","timeout:1"]}`
+	testTask := &Task{
+		Containers: []*apicontainer.Container{
+			{Name: "c1", DockerConfig: apicontainer.DockerConfig{HostConfig: &hostConfig}},
+			{Name: "c2", DockerConfig: apicontainer.DockerConfig{HostConfig: &otherHostConfig}},
+			{Name: "c3"},
+		},
+	}
+	testTask.NetworkMode = AWSVPCNetworkMode
+	testTask.AddTaskENI(&ni.NetworkInterface{
+		ID:                           "TestBuildCNIConfigDNS",
+		MacAddress:                   mac,
+		InterfaceAssociationProtocol: ni.DefaultInterfaceAssociationProtocol,
+		SubnetGatewayIPV4Address:     "10.0.1.0/24",
+		IPV4Addresses: []*ni.IPV4Address{
+			{
+				Primary: true,
+				Address: ipv4,
+			},
+		},
+		DomainNameServers:    []string{"10.0.0.2"},
+		DomainNameSearchList: []string{"corp.example.com"},
+	})
+
+	cniConfig, err := testTask.BuildCNIConfigAwsvpc(true, &ecscni.Config{
+		MinSupportedCNIVersion: "latest",
+	})
+	require.NoError(t, err)
+	require.Len(t, cniConfig.NetworkConfigs, 2)
+	var eniConfig ecscni.VPCENIPluginConfig
+	err = json.Unmarshal(cniConfig.NetworkConfigs[0].CNINetworkConfig.Bytes, &eniConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.2"}, eniConfig.DNS.Nameservers)
+	assert.Equal(t, []string{"corp.example.com"}, eniConfig.DNS.Search)
+	assert.Equal(t, []string{"ndots:2", "timeout:1"}, eniConfig.DNS.Options)
+}
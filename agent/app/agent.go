@@ -249,7 +249,7 @@ func newAgent(blackholeEC2Metadata bool, acceptInsecureCert *bool) (agent, error
 		pauseLoader:                 pause.New(),
 		serviceconnectManager:       engineserviceconnect.NewManager(),
 		daemonManagers:              make(map[string]dm.DaemonManager),
-		cniClient:                   ecscni.NewClient(cfg.CNIPluginsPath),
+		cniClient:                   ecscni.NewClientWithPluginPathResolver(cfg.CNIPluginsPath, ecscni.NewPluginPathResolver(cfg.CNIPluginsPath, cfg.CNIPluginPaths)),
 		metadataManager:             metadataManager,
 		terminationHandler:          sighandlers.StartDefaultTerminationHandler,
 		mobyPlugins:                 mobypkgwrapper.NewPlugins(),
@@ -295,7 +295,8 @@ func (agent *ecsAgent) start() int {
 		return exitcodes.ExitError
 	}
 	clientFactory := ecsclient.NewECSClientFactory(agent.credentialProvider, cfgAccessor, agent.ec2MetadataClient,
-		version.String(), ecsclient.WithIPv6PortBindingExcluded(true))
+		version.String(), ecsclient.WithIPv6PortBindingExcluded(true),
+		ecsclient.WithStateChangeDryRun(agent.cfg.StateChangeDryRun.Enabled()))
 	client, err := clientFactory.NewClient()
 	if err != nil {
 		logger.Critical("Unable to create new ECS client", logger.Fields{
@@ -523,6 +523,8 @@ func environmentConfig() (Config, error) {
 
 	additionalLocalRoutes, errs := parseAdditionalLocalRoutes(errs)
 
+	cniPluginPaths, errs := parseCNIPluginPaths(errs)
+
 	var err error
 	if len(errs) > 0 {
 		err = apierrors.NewMultiError(errs...)
@@ -572,6 +574,13 @@ func environmentConfig() (Config, error) {
 		ImageCleanupExclusionList:           parseImageCleanupExclusionList("ECS_EXCLUDE_UNTRACKED_IMAGE"),
 		InstanceAttributes:                  instanceAttributes,
 		CNIPluginsPath:                      os.Getenv("ECS_CNI_PLUGINS_PATH"),
+		CNIPluginPaths:                      cniPluginPaths,
+		TaskNetworkSetupBackoffMin:          parseEnvVariableDuration("ECS_TASK_NETWORK_SETUP_BACKOFF_MIN"),
+		TaskNetworkSetupBackoffMax:          parseEnvVariableDuration("ECS_TASK_NETWORK_SETUP_BACKOFF_MAX"),
+		TaskNetworkSetupMaxRetryCount:       int(parseEnvVariableUint16("ECS_TASK_NETWORK_SETUP_MAX_RETRY_COUNT")),
+		TaskNetworkSetupTimeout:             parseEnvVariableDuration("ECS_TASK_NETWORK_SETUP_TIMEOUT"),
+		TaskNetworkSetupInitialDelayMax:     parseEnvVariableDuration("ECS_TASK_NETWORK_SETUP_INITIAL_DELAY_MAX"),
+		VerifyTaskENISetup:                  parseBooleanDefaultFalseConfig("ECS_VERIFY_TASK_ENI_SETUP"),
 		AWSVPCBlockInstanceMetdata:          parseBooleanDefaultFalseConfig("ECS_AWSVPC_BLOCK_IMDS"),
 		AWSVPCAdditionalLocalRoutes:         additionalLocalRoutes,
 		ContainerMetadataEnabled:            parseBooleanDefaultFalseConfig("ECS_ENABLE_CONTAINER_METADATA"),
@@ -603,6 +612,8 @@ func environmentConfig() (Config, error) {
 		WarmPoolsSupport:                    parseBooleanDefaultFalseConfig("ECS_WARM_POOLS_CHECK"),
 		DynamicHostPortRange:                parseDynamicHostPortRange("ECS_DYNAMIC_HOST_PORT_RANGE"),
 		TaskPidsLimit:                       parseTaskPidsLimit(),
+		StateChangeDryRun:                   parseBooleanDefaultFalseConfig("ECS_STATE_CHANGE_DRY_RUN"),
+		RuntimePlatformVersion:              os.Getenv("ECS_RUNTIME_PLATFORM_VERSION"),
 	}, err
 }
 
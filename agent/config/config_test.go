@@ -704,6 +704,67 @@ func TestEnableRuntimeStatsConfigEnabled(t *testing.T) {
 	assert.True(t, cfg.EnableRuntimeStats.Enabled(), "Wrong value for EnableRuntimeStats")
 }
 
+func TestStateChangeDryRunConfig(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.False(t, cfg.StateChangeDryRun.Enabled(), "Default StateChangeDryRun set incorrectly")
+
+	defer setTestEnv("ECS_STATE_CHANGE_DRY_RUN", "true")()
+	cfg, err = NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.StateChangeDryRun.Enabled(), "Wrong value for StateChangeDryRun")
+}
+
+func TestRuntimePlatformVersionConfig(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.RuntimePlatformVersion, "Default RuntimePlatformVersion set incorrectly")
+
+	defer setTestEnv("ECS_RUNTIME_PLATFORM_VERSION", "1.4.0")()
+	cfg, err = NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, "1.4.0", cfg.RuntimePlatformVersion, "Wrong value for RuntimePlatformVersion")
+}
+
+func TestTaskNetworkSetupRetryConfig(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.TaskNetworkSetupBackoffMin, "Default TaskNetworkSetupBackoffMin set incorrectly")
+	assert.Zero(t, cfg.TaskNetworkSetupBackoffMax, "Default TaskNetworkSetupBackoffMax set incorrectly")
+	assert.Zero(t, cfg.TaskNetworkSetupMaxRetryCount, "Default TaskNetworkSetupMaxRetryCount set incorrectly")
+	assert.Zero(t, cfg.TaskNetworkSetupTimeout, "Default TaskNetworkSetupTimeout set incorrectly")
+	assert.Zero(t, cfg.TaskNetworkSetupInitialDelayMax, "Default TaskNetworkSetupInitialDelayMax set incorrectly")
+
+	defer setTestEnv("ECS_TASK_NETWORK_SETUP_BACKOFF_MIN", "2s")()
+	defer setTestEnv("ECS_TASK_NETWORK_SETUP_BACKOFF_MAX", "30s")()
+	defer setTestEnv("ECS_TASK_NETWORK_SETUP_MAX_RETRY_COUNT", "3")()
+	defer setTestEnv("ECS_TASK_NETWORK_SETUP_TIMEOUT", "5m")()
+	defer setTestEnv("ECS_TASK_NETWORK_SETUP_INITIAL_DELAY_MAX", "10s")()
+	cfg, err = NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Second, cfg.TaskNetworkSetupBackoffMin, "Wrong value for TaskNetworkSetupBackoffMin")
+	assert.Equal(t, 30*time.Second, cfg.TaskNetworkSetupBackoffMax, "Wrong value for TaskNetworkSetupBackoffMax")
+	assert.Equal(t, 3, cfg.TaskNetworkSetupMaxRetryCount, "Wrong value for TaskNetworkSetupMaxRetryCount")
+	assert.Equal(t, 5*time.Minute, cfg.TaskNetworkSetupTimeout, "Wrong value for TaskNetworkSetupTimeout")
+	assert.Equal(t, 10*time.Second, cfg.TaskNetworkSetupInitialDelayMax,
+		"Wrong value for TaskNetworkSetupInitialDelayMax")
+}
+
+func TestVerifyTaskENISetupConfig(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.False(t, cfg.VerifyTaskENISetup.Enabled(), "Default VerifyTaskENISetup set incorrectly")
+
+	defer setTestEnv("ECS_VERIFY_TASK_ENI_SETUP", "true")()
+	cfg, err = NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.VerifyTaskENISetup.Enabled(), "Wrong value for VerifyTaskENISetup")
+}
+
 func TestParseImagePullBehavior(t *testing.T) {
 	testcases := []struct {
 		name                      string
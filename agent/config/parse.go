@@ -331,6 +331,22 @@ func parseContainerInstanceTags(errs []error) (map[string]string, []error) {
 	return containerInstanceTags, errs
 }
 
+func parseCNIPluginPaths(errs []error) (map[string]string, []error) {
+	var cniPluginPaths map[string]string
+	cniPluginPathsConfigString := os.Getenv("ECS_CNI_PLUGIN_PATHS")
+	if cniPluginPathsConfigString == "" {
+		return cniPluginPaths, errs
+	}
+
+	err := json.Unmarshal([]byte(cniPluginPathsConfigString), &cniPluginPaths)
+	if err != nil {
+		wrappedErr := fmt.Errorf("Invalid format for ECS_CNI_PLUGIN_PATHS. Expected a json hash: %v", err)
+		seelog.Error(wrappedErr)
+		errs = append(errs, wrappedErr)
+	}
+	return cniPluginPaths, errs
+}
+
 func parseContainerInstancePropagateTagsFrom() ContainerInstancePropagateTagsFromType {
 	containerInstancePropagateTagsFromString := os.Getenv("ECS_CONTAINER_INSTANCE_PROPAGATE_TAGS_FROM")
 	switch containerInstancePropagateTagsFromString {
@@ -137,3 +137,21 @@ func TestParseContainerInstanceTags(t *testing.T) {
 	assert.Equal(t, expectedInvalid, actual)
 	assert.Equal(t, expectedErrs, actualErrs)
 }
+
+func TestParseCNIPluginPaths(t *testing.T) {
+	// empty
+	t.Setenv("ECS_CNI_PLUGIN_PATHS", "")
+	actual, actualErrs := parseCNIPluginPaths(nil)
+	assert.Nil(t, actual)
+	assert.Empty(t, actualErrs)
+	// with valid values
+	t.Setenv("ECS_CNI_PLUGIN_PATHS", `{"vpc-eni.exe":"D:\\cni\\vpc-eni.exe"}`)
+	actual, actualErrs = parseCNIPluginPaths(nil)
+	assert.Equal(t, map[string]string{"vpc-eni.exe": `D:\cni\vpc-eni.exe`}, actual)
+	assert.Empty(t, actualErrs)
+	// with invalid values
+	t.Setenv("ECS_CNI_PLUGIN_PATHS", `{"vpc-eni.exe":}`)
+	expectedErrs := []error{fmt.Errorf("Invalid format for ECS_CNI_PLUGIN_PATHS. Expected a json hash: invalid character '}' looking for beginning of value")}
+	_, actualErrs = parseCNIPluginPaths(nil)
+	assert.Equal(t, expectedErrs, actualErrs)
+}
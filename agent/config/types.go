@@ -224,6 +224,11 @@ type Config struct {
 	// CNIPluginsPath is the path for the cni plugins
 	CNIPluginsPath string
 
+	// CNIPluginPaths maps the names of cni plugin executables to the absolute paths they are
+	// installed at, for plugins that aren't installed in CNIPluginsPath. It can be set by the
+	// ECS_CNI_PLUGIN_PATHS environment variable as a json hash.
+	CNIPluginPaths map[string]string
+
 	// PauseContainerTarballPath is the path to the pause container tarball
 	PauseContainerTarballPath string
 
@@ -358,6 +363,35 @@ type Config struct {
 	// Currently, this field is only populated for Windows and is used during task networking setup.
 	InstanceENIDNSServerList []string
 
+	// TaskNetworkSetupBackoffMin and TaskNetworkSetupBackoffMax bound the delay between the attempts
+	// to set up the network namespace of awsvpc tasks, and TaskNetworkSetupMaxRetryCount is the maximum
+	// number of attempts. Zero values use the defaults. They can be set by the
+	// ECS_TASK_NETWORK_SETUP_BACKOFF_MIN, ECS_TASK_NETWORK_SETUP_BACKOFF_MAX and
+	// ECS_TASK_NETWORK_SETUP_MAX_RETRY_COUNT environment variables. Currently, they are only honored
+	// on Windows, where the namespace setup is retried.
+	TaskNetworkSetupBackoffMin    time.Duration
+	TaskNetworkSetupBackoffMax    time.Duration
+	TaskNetworkSetupMaxRetryCount int
+
+	// TaskNetworkSetupTimeout is a hard ceiling on the total time spent setting up the network
+	// namespace of awsvpc tasks, including all attempts and the delays between them. Zero means the
+	// setup is only bounded by the number of attempts. It can be set by the
+	// ECS_TASK_NETWORK_SETUP_TIMEOUT environment variable. Currently, it's only honored on Windows.
+	TaskNetworkSetupTimeout time.Duration
+
+	// TaskNetworkSetupInitialDelayMax bounds a random delay applied before the first attempt to set
+	// up the network namespace of awsvpc tasks, which spreads the setup of tasks starting at the same
+	// time on dense hosts. Zero means the first attempt is made immediately. It can be set by the
+	// ECS_TASK_NETWORK_SETUP_INITIAL_DELAY_MAX environment variable. Currently, it's only honored on
+	// Windows.
+	TaskNetworkSetupInitialDelayMax time.Duration
+
+	// VerifyTaskENISetup specifies whether the agent should verify that the adapter of the task ENI
+	// has been assigned the configured IP addresses once the vpc-eni plugin has set it up, failing
+	// the setup if it hasn't. It's disabled by default, and can be enabled by the
+	// ECS_VERIFY_TASK_ENI_SETUP environment variable. Currently, it's only honored on Windows.
+	VerifyTaskENISetup BooleanDefaultFalse
+
 	// RuntimeStatsLogFile stores the path where the golang runtime stats are periodically logged
 	RuntimeStatsLogFile string
 
@@ -384,4 +418,17 @@ type Config struct {
 	// cgroup setting at the ECS task level.
 	// see https://www.kernel.org/doc/html/latest/admin-guide/cgroup-v2.html#pid
 	TaskPidsLimit int
+
+	// StateChangeDryRun specifies whether the agent should build and validate the state changes
+	// as usual but only log them instead of submitting them to ECS. This is meant for validating
+	// the configuration and connectivity of an instance without mutating backend state. This
+	// configuration is set to false by default, and can be overridden by the
+	// ECS_STATE_CHANGE_DRY_RUN environment variable.
+	StateChangeDryRun BooleanDefaultFalse
+
+	// RuntimePlatformVersion is the version of the runtime platform the instance runs, which is
+	// reported in the task state changes for correlating task behavior with runtime platform
+	// rollouts. It's unset by default, and can be set by the ECS_RUNTIME_PLATFORM_VERSION
+	// environment variable.
+	RuntimePlatformVersion string
 }
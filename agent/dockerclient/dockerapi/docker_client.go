@@ -966,6 +966,7 @@ func MetadataFromContainer(dockerContainer *types.ContainerJSON) DockerContainer
 	if dockerContainer.State == nil {
 		return metadata
 	}
+	metadata.PID = dockerContainer.State.Pid
 	if !dockerContainer.State.Running && !finishedTime.IsZero() {
 		// Only record an exitcode if it has exited
 		metadata.ExitCode = &dockerContainer.State.ExitCode
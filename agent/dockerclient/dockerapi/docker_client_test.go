@@ -2008,6 +2008,7 @@ func TestMetadataFromContainer(t *testing.T) {
 			Created: created,
 			State: &types.ContainerState{
 				Running:    true,
+				Pid:        4242,
 				StartedAt:  started,
 				FinishedAt: finished,
 			},
@@ -2027,6 +2028,7 @@ func TestMetadataFromContainer(t *testing.T) {
 	assert.Equal(t, labels, metadata.Labels)
 	assert.Len(t, metadata.PortBindings, 1)
 	assert.Equal(t, "bridge", metadata.NetworkMode)
+	assert.Equal(t, 4242, metadata.PID)
 	assert.NotNil(t, metadata.NetworkSettings)
 	assert.Equal(t, "17.0.0.3", metadata.NetworkSettings.IPAddress)
 
@@ -70,6 +70,9 @@ type DockerContainerMetadata struct {
 	StartedAt time.Time
 	// FinishedAt is the timestamp of container stop
 	FinishedAt time.Time
+	// PID is the host PID of the container's init process, it's zero if the
+	// container isn't running
+	PID int
 	// Health contains the result of a container health check
 	Health apicontainer.HealthStatus
 	// NetworkMode denotes the network mode in which the container is started
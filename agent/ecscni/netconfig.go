@@ -15,13 +15,19 @@ package ecscni
 
 import (
 	"encoding/json"
+	"net"
 
+	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 
 	"github.com/containernetworking/cni/libcni"
 	cniTypes "github.com/containernetworking/cni/pkg/types"
+	"github.com/pkg/errors"
 )
 
+// macAddressLength is the number of octets of an ENI MAC address.
+const macAddressLength = 6
+
 // newNetworkConfig converts a network config to libcni's NetworkConfig.
 func newNetworkConfig(netcfg interface{}, plugin string, cniVersion string) (*libcni.NetworkConfig, error) {
 	configBytes, err := json.Marshal(netcfg)
@@ -45,3 +51,102 @@ func newNetworkConfig(netcfg interface{}, plugin string, cniVersion string) (*li
 
 	return netConfig, nil
 }
+
+// NormalizeMAC validates that the given string is a 6-octet MAC address and returns it
+// in its canonical form, lowercase and colon-separated. Addresses separated by colons
+// or hyphens, e.g. AA-BB-CC-DD-EE-FF, are accepted in either case.
+func NormalizeMAC(mac string) (string, error) {
+	hwAddr, err := net.ParseMAC(mac)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid mac address %q", mac)
+	}
+	if len(hwAddr) != macAddressLength {
+		return "", errors.Errorf("invalid mac address %q: expected %d octets, got %d",
+			mac, macAddressLength, len(hwAddr))
+	}
+	return hwAddr.String(), nil
+}
+
+// VPCENIPluginConfigBuilder builds the configuration of the vpc-eni plugin, applying the
+// defaults and validating that the required fields are set.
+type VPCENIPluginConfigBuilder struct {
+	conf VPCENIPluginConfig
+}
+
+// NewVPCENIPluginConfigBuilder returns a builder for a vpc-eni plugin configuration.
+func NewVPCENIPluginConfigBuilder() *VPCENIPluginConfigBuilder {
+	return &VPCENIPluginConfigBuilder{
+		conf: VPCENIPluginConfig{
+			Type: VPCENIPluginName,
+		},
+	}
+}
+
+// WithCNIVersion sets the cni spec version. The minimum supported version is used if it
+// is not set.
+func (b *VPCENIPluginConfigBuilder) WithCNIVersion(cniVersion string) *VPCENIPluginConfigBuilder {
+	b.conf.CNIVersion = cniVersion
+	return b
+}
+
+// WithENI sets the name, MAC address and IP addresses of the eni to create an endpoint for.
+func (b *VPCENIPluginConfigBuilder) WithENI(name, macAddress string, ipAddresses []string) *VPCENIPluginConfigBuilder {
+	b.conf.ENIName = name
+	b.conf.ENIMACAddress = macAddress
+	b.conf.ENIIPAddresses = copyStrings(ipAddresses)
+	return b
+}
+
+// WithIPv6 sets the ipv6 address of the eni and of its subnet gateway.
+func (b *VPCENIPluginConfigBuilder) WithIPv6(address, gatewayAddress string) *VPCENIPluginConfigBuilder {
+	b.conf.ENIIPV6Address = address
+	b.conf.GatewayIPV6Address = gatewayAddress
+	return b
+}
+
+// WithGateway sets the IPv4 addresses of the subnet gateway for the eni.
+func (b *VPCENIPluginConfigBuilder) WithGateway(gatewayIPAddresses []string) *VPCENIPluginConfigBuilder {
+	b.conf.GatewayIPAddresses = copyStrings(gatewayIPAddresses)
+	return b
+}
+
+// WithDNS sets the nameservers, search domains and resolver options passed to the plugin.
+func (b *VPCENIPluginConfigBuilder) WithDNS(nameservers, search, options []string) *VPCENIPluginConfigBuilder {
+	b.conf.WithDNS(nameservers, search, options)
+	return b
+}
+
+// WithExistingNetwork makes the plugin create the endpoint in an existing network instead
+// of creating a new one for an eni.
+func (b *VPCENIPluginConfigBuilder) WithExistingNetwork() *VPCENIPluginConfigBuilder {
+	b.conf.UseExistingNetwork = true
+	return b
+}
+
+// WithBlockIMDS sets whether the IMDS should be blocked for the created endpoint.
+func (b *VPCENIPluginConfigBuilder) WithBlockIMDS(blockIMDS bool) *VPCENIPluginConfigBuilder {
+	b.conf.BlockIMDS = blockIMDS
+	return b
+}
+
+// Build returns the vpc-eni plugin configuration. It returns an error if the eni MAC
+// address, IP addresses or gateway addresses are missing when not using an existing
+// network.
+func (b *VPCENIPluginConfigBuilder) Build() (*VPCENIPluginConfig, error) {
+	conf := b.conf
+	if conf.CNIVersion == "" {
+		conf.CNIVersion = config.DefaultMinSupportedCNIVersion
+	}
+	if !conf.UseExistingNetwork {
+		if conf.ENIMACAddress == "" {
+			return nil, errors.New("invalid vpc-eni plugin configuration: eni mac address must be specified")
+		}
+		if len(conf.ENIIPAddresses) == 0 {
+			return nil, errors.New("invalid vpc-eni plugin configuration: eni ip addresses must be specified")
+		}
+		if len(conf.GatewayIPAddresses) == 0 {
+			return nil, errors.New("invalid vpc-eni plugin configuration: gateway ip addresses must be specified")
+		}
+	}
+	return &conf, nil
+}
@@ -114,12 +114,14 @@ func newIPAMConfig(cfg *Config) (IPAMConfig, error) {
 
 // NewVPCENINetworkConfig creates a new vpc-eni CNI plugin configuration.
 func NewVPCENINetworkConfig(eni *ni.NetworkInterface, cfg *Config) (string, *libcni.NetworkConfig, error) {
-	eniConf := VPCENIPluginConfig{
-		Type:               VPCENIPluginName,
-		ENIMACAddress:      eni.MacAddress,
-		ENIIPAddresses:     eni.GetIPAddressesWithPrefixLength(),
-		GatewayIPAddresses: []string{eni.GetSubnetGatewayIPv4Address()},
-		BlockIMDS:          cfg.BlockInstanceMetadata,
+	eniConf, err := NewVPCENIPluginConfigBuilder().
+		WithCNIVersion(cfg.MinSupportedCNIVersion).
+		WithENI("", eni.MacAddress, eni.GetIPAddressesWithPrefixLength()).
+		WithGateway([]string{eni.GetSubnetGatewayIPv4Address()}).
+		WithBlockIMDS(cfg.BlockInstanceMetadata).
+		Build()
+	if err != nil {
+		return "", nil, fmt.Errorf("cni config: failed to create configuration: %w", err)
 	}
 
 	networkConfig, err := newNetworkConfig(eniConf, VPCENIPluginName, cfg.MinSupportedCNIVersion)
@@ -0,0 +1,107 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecscni
+
+import (
+	"testing"
+
+	"github.com/containernetworking/cni/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeMAC(t *testing.T) {
+	for _, mac := range []string{"AA-BB-CC-DD-EE-FF", "aa:bb:cc:dd:ee:ff", "AA:BB:CC:DD:EE:FF", "aa-bb-cc-dd-ee-ff"} {
+		t.Run(mac, func(t *testing.T) {
+			normalized, err := NormalizeMAC(mac)
+			require.NoError(t, err)
+			assert.Equal(t, "aa:bb:cc:dd:ee:ff", normalized)
+		})
+	}
+}
+
+func TestNormalizeMACInvalid(t *testing.T) {
+	for _, mac := range []string{"", "12:34;56-78", "aa:bb:cc:dd:ee", "aa:bb:cc:dd:ee:gg", "00:00:5e:00:53:00:00:01"} {
+		t.Run(mac, func(t *testing.T) {
+			_, err := NormalizeMAC(mac)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestVPCENIPluginConfigBuilder(t *testing.T) {
+	ipAddresses := []string{"172.31.21.40/20"}
+	conf, err := NewVPCENIPluginConfigBuilder().
+		WithENI("eni-12345678", "02:7b:64:49:b1:40", ipAddresses).
+		WithGateway([]string{"172.31.16.1"}).
+		WithDNS([]string{"172.31.0.2"}, []string{"example.com"}, nil).
+		WithBlockIMDS(true).
+		Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, &VPCENIPluginConfig{
+		Type:               VPCENIPluginName,
+		CNIVersion:         "0.3.0",
+		DNS:                types.DNS{Nameservers: []string{"172.31.0.2"}, Search: []string{"example.com"}},
+		ENIName:            "eni-12345678",
+		ENIMACAddress:      "02:7b:64:49:b1:40",
+		ENIIPAddresses:     []string{"172.31.21.40/20"},
+		GatewayIPAddresses: []string{"172.31.16.1"},
+		BlockIMDS:          true,
+	}, conf)
+
+	// The builder copies the given lists.
+	ipAddresses[0] = "10.0.0.1/24"
+	assert.Equal(t, []string{"172.31.21.40/20"}, conf.ENIIPAddresses)
+}
+
+func TestVPCENIPluginConfigBuilderCNIVersion(t *testing.T) {
+	conf, err := NewVPCENIPluginConfigBuilder().WithExistingNetwork().WithCNIVersion("1.0.0").Build()
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", conf.CNIVersion)
+	assert.True(t, conf.UseExistingNetwork)
+}
+
+func TestVPCENIPluginConfigBuilderMissingFields(t *testing.T) {
+	testCases := []struct {
+		name    string
+		builder *VPCENIPluginConfigBuilder
+	}{
+		{
+			name:    "no eni",
+			builder: NewVPCENIPluginConfigBuilder().WithGateway([]string{"172.31.16.1"}),
+		},
+		{
+			name: "no eni ip addresses",
+			builder: NewVPCENIPluginConfigBuilder().
+				WithENI("eni-12345678", "02:7b:64:49:b1:40", nil).
+				WithGateway([]string{"172.31.16.1"}),
+		},
+		{
+			name: "no gateway",
+			builder: NewVPCENIPluginConfigBuilder().
+				WithENI("eni-12345678", "02:7b:64:49:b1:40", []string{"172.31.21.40/20"}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.builder.Build()
+			assert.Error(t, err)
+		})
+	}
+}
@@ -17,12 +17,12 @@
 package ecscni
 
 import (
+	"net"
 	"regexp"
 
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	"github.com/cihub/seelog"
 	"github.com/containernetworking/cni/libcni"
-	"github.com/containernetworking/cni/pkg/types"
 	"github.com/pkg/errors"
 )
 
@@ -35,29 +35,54 @@ const (
 
 // NewVPCENIPluginConfigForTaskNSSetup is used to create the configuration of vpc-eni plugin for task namespace setup.
 func NewVPCENIPluginConfigForTaskNSSetup(eni *ni.NetworkInterface, cfg *Config) (*libcni.NetworkConfig, error) {
-	// Use the DNS server addresses of the instance ENI it would belong in the same VPC as
-	// the task ENI and therefore, have same DNS configuration.
-	dns := types.DNS{
-		Nameservers: cfg.InstanceENIDNSServerList,
+	// The plugin matches the MAC address against the one reported by HCN, so pass it in
+	// its canonical form regardless of the format it was received in.
+	macAddress, err := NormalizeMAC(eni.MacAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create vpc-eni plugin configuration for setting up "+
+			"task network namespace")
 	}
 
 	// Validate MAC Address, ENI IP Address and ENI Gateway address used for CNI plugin configuration.
 	// Other params are generated at runtime and are considered safe.
-	if !isValid(eni.MacAddress) || !isValid(eni.GetPrimaryIPv4AddressWithPrefixLength()) ||
+	if !isValid(macAddress) || !isValid(eni.GetPrimaryIPv4AddressWithPrefixLength()) ||
 		!isValid(eni.GetSubnetGatewayIPv4Address()) {
 		return nil, errors.New("failed to create vpc-eni plugin configuration for setting up " +
 			"task network namespace due to failed data validation")
 	}
 
-	eniConf := VPCENIPluginConfig{
-		Type:               VPCENIPluginName,
-		DNS:                dns,
-		ENIName:            eni.GetLinkName(),
-		ENIMACAddress:      eni.MacAddress,
-		ENIIPAddresses:     []string{eni.GetPrimaryIPv4AddressWithPrefixLength()},
-		GatewayIPAddresses: []string{eni.GetSubnetGatewayIPv4Address()},
-		UseExistingNetwork: false,
-		BlockIMDS:          cfg.BlockInstanceMetadata,
+	builder := NewVPCENIPluginConfigBuilder().
+		WithCNIVersion(cfg.MinSupportedCNIVersion).
+		WithENI(eni.GetLinkName(), macAddress, []string{eni.GetPrimaryIPv4AddressWithPrefixLength()}).
+		WithGateway([]string{eni.GetSubnetGatewayIPv4Address()}).
+		WithBlockIMDS(cfg.BlockInstanceMetadata)
+
+	// Use the DNS configuration of the task ENI if it has one. Otherwise, use the DNS server
+	// addresses of the instance ENI as it would belong in the same VPC as the task ENI and
+	// therefore, have the same DNS configuration. This keeps any DNS servers added for Active
+	// Directory available to the task, which gMSA relies on.
+	nameservers := eni.DomainNameServers
+	if len(nameservers) == 0 {
+		nameservers = cfg.InstanceENIDNSServerList
+	}
+	builder.WithDNS(nameservers, eni.DomainNameSearchList, cfg.TaskDNSOptions)
+
+	// Pass the IPv6 address and gateway to the plugin for dual-stack ENIs.
+	if len(eni.IPV6Addresses) > 0 {
+		ipv6Address, ipv6Gateway, err := getIPv6AddressAndGateway(eni)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create vpc-eni plugin configuration for setting up "+
+				"task network namespace due to failed data validation")
+		}
+		builder.WithIPv6(ipv6Address, ipv6Gateway)
+	}
+
+	eniConf, err := builder.Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create vpc-eni plugin configuration for setting up task network namespace")
+	}
+	if err := eniConf.Validate(); err != nil {
+		return nil, errors.Wrap(err, "failed to create vpc-eni plugin configuration for setting up task network namespace")
 	}
 
 	networkConfig, err := newNetworkConfig(eniConf, ECSVPCENIPluginExecutable, cfg.MinSupportedCNIVersion)
@@ -71,10 +96,16 @@ func NewVPCENIPluginConfigForTaskNSSetup(eni *ni.NetworkInterface, cfg *Config)
 
 // NewVPCENIPluginConfigForECSBridgeSetup creates the configuration required by vpc-eni plugin to setup ecs-bridge endpoint for the task.
 func NewVPCENIPluginConfigForECSBridgeSetup(cfg *Config) (*libcni.NetworkConfig, error) {
-	bridgeConf := VPCENIPluginConfig{
-		Type:               VPCENIPluginName,
-		UseExistingNetwork: true,
-		BlockIMDS:          cfg.BlockInstanceMetadata,
+	bridgeConf, err := NewVPCENIPluginConfigBuilder().
+		WithCNIVersion(cfg.MinSupportedCNIVersion).
+		WithExistingNetwork().
+		WithBlockIMDS(cfg.BlockInstanceMetadata).
+		Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create vpc-eni plugin configuration for setting up ecs-bridge endpoint of the task")
+	}
+	if err := bridgeConf.Validate(); err != nil {
+		return nil, errors.Wrap(err, "failed to create vpc-eni plugin configuration for setting up ecs-bridge endpoint of the task")
 	}
 
 	networkConfig, err := newNetworkConfig(bridgeConf, ECSVPCENIPluginExecutable, cfg.MinSupportedCNIVersion)
@@ -86,6 +117,50 @@ func NewVPCENIPluginConfigForECSBridgeSetup(cfg *Config) (*libcni.NetworkConfig,
 	return networkConfig, nil
 }
 
+// Validate checks the vpc-eni plugin configuration for incompatible combinations of fields
+// before the plugin is invoked with it. The plugin either creates an endpoint for a task ENI
+// in a new network, which requires the ENI to be specified, or creates an endpoint in an
+// existing network, in which case no ENI must be specified.
+func (conf *VPCENIPluginConfig) Validate() error {
+	if conf.UseExistingNetwork {
+		if conf.ENIName != "" || conf.ENIMACAddress != "" || len(conf.ENIIPAddresses) > 0 ||
+			conf.ENIIPV6Address != "" {
+			return errors.New("invalid vpc-eni plugin configuration: eni must not be specified " +
+				"when using an existing network")
+		}
+		return nil
+	}
+
+	if conf.ENIName == "" {
+		return errors.New("invalid vpc-eni plugin configuration: eni name must be specified " +
+			"when not using an existing network")
+	}
+	if len(conf.ENIIPAddresses) != len(conf.GatewayIPAddresses) {
+		return errors.Errorf("invalid vpc-eni plugin configuration: %d eni ip addresses "+
+			"specified with %d gateway ip addresses", len(conf.ENIIPAddresses), len(conf.GatewayIPAddresses))
+	}
+	if (conf.ENIIPV6Address == "") != (conf.GatewayIPV6Address == "") {
+		return errors.New("invalid vpc-eni plugin configuration: eni ipv6 address and " +
+			"gateway ipv6 address must be specified together")
+	}
+	return nil
+}
+
+// getIPv6AddressAndGateway returns the primary IPv6 address of the ENI with its prefix length, along with
+// the IPv6 address of the subnet gateway, which is the first address of the ENI's IPv6 subnet.
+func getIPv6AddressAndGateway(eni *ni.NetworkInterface) (string, string, error) {
+	ipv6Address := eni.IPV6Addresses[0].Address + "/" + ni.IPv6SubnetPrefixLength
+	ip, ipNet, err := net.ParseCIDR(ipv6Address)
+	if err != nil || ip.To4() != nil {
+		return "", "", errors.Errorf("invalid ipv6 address: %s", ipv6Address)
+	}
+
+	gateway := make(net.IP, len(ipNet.IP))
+	copy(gateway, ipNet.IP)
+	gateway[len(gateway)-1] |= 1
+	return ipv6Address, gateway.String(), nil
+}
+
 // isValid validates if the data length is within the acceptable limits and has valid characters.
 func isValid(data string) bool {
 	allowedPattern, err := regexp.Compile(allowedRegexPattern)
@@ -21,7 +21,9 @@ import (
 	"testing"
 
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/containernetworking/cni/pkg/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -34,6 +36,9 @@ const (
 	mac                     = "02:7b:64:49:b1:40"
 	cniMinSupportedVersion  = "1.0.0"
 	invalidMACAddress       = "12:34;56-78"
+	ipv6                    = "2600:1f13:4d9:e602:6aea:cdb1:2b2b:8d62"
+	ipv6CIDR                = "2600:1f13:4d9:e602:6aea:cdb1:2b2b:8d62/64"
+	ipv6Gateway             = "2600:1f13:4d9:e602::1"
 )
 
 func getTaskENI() *ni.NetworkInterface {
@@ -73,6 +78,7 @@ func TestNewVPCENIPluginConfigForTaskNSSetup(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, ECSVPCENIPluginExecutable, config.Network.Type)
 	assert.EqualValues(t, cniMinSupportedVersion, config.Network.CNIVersion)
+	assert.EqualValues(t, cniMinSupportedVersion, netConfig.CNIVersion)
 	assert.EqualValues(t, []string{validDNSServer}, netConfig.DNS.Nameservers)
 	assert.EqualValues(t, TaskHNSNetworkNamePrefix, config.Network.Name)
 	assert.EqualValues(t, []string{ipv4CIDR}, netConfig.ENIIPAddresses)
@@ -83,6 +89,86 @@ func TestNewVPCENIPluginConfigForTaskNSSetup(t *testing.T) {
 	assert.EqualValues(t, cniConfig.BlockInstanceMetadata, netConfig.BlockIMDS)
 }
 
+// TestNewVPCENIPluginConfigForTaskNSSetupTaskDNS tests that the nameservers and search domains of the
+// task ENI are passed to the plugin in place of the DNS servers of the instance ENI, along with the
+// resolver options of the task.
+func TestNewVPCENIPluginConfigForTaskNSSetupTaskDNS(t *testing.T) {
+	taskENI := getTaskENI()
+	taskENI.DomainNameServers = []string{"10.0.0.3"}
+	taskENI.DomainNameSearchList = []string{"corp.example.com", "example.com"}
+	cniConfig := getCNIConfig()
+	cniConfig.TaskDNSOptions = []string{"ndots:2"}
+	config, err := NewVPCENIPluginConfigForTaskNSSetup(taskENI, cniConfig)
+	require.NoError(t, err)
+
+	netConfig := &VPCENIPluginConfig{}
+	require.NoError(t, json.Unmarshal(config.Bytes, netConfig))
+	assert.Equal(t, []string{"10.0.0.3"}, netConfig.DNS.Nameservers)
+	assert.Equal(t, []string{"corp.example.com", "example.com"}, netConfig.DNS.Search)
+	assert.Equal(t, []string{"ndots:2"}, netConfig.DNS.Options)
+}
+
+// TestNewVPCENIPluginConfigForTaskNSSetupNoDNS tests that an empty DNS configuration is passed to the
+// plugin when neither the task nor the instance ENI have a DNS configuration.
+func TestNewVPCENIPluginConfigForTaskNSSetupNoDNS(t *testing.T) {
+	taskENI := getTaskENI()
+	cniConfig := getCNIConfig()
+	cniConfig.InstanceENIDNSServerList = nil
+	config, err := NewVPCENIPluginConfigForTaskNSSetup(taskENI, cniConfig)
+	require.NoError(t, err)
+
+	netConfig := &VPCENIPluginConfig{}
+	require.NoError(t, json.Unmarshal(config.Bytes, netConfig))
+	assert.Equal(t, types.DNS{}, netConfig.DNS)
+}
+
+// TestVPCENIPluginConfigWithDNS tests that WithDNS copies the given DNS configuration.
+func TestVPCENIPluginConfigWithDNS(t *testing.T) {
+	search := []string{"example.com"}
+	conf := (&VPCENIPluginConfig{}).WithDNS([]string{validDNSServer}, search, []string{"ndots:2"})
+	search[0] = "changed.example.com"
+
+	assert.Equal(t, types.DNS{
+		Nameservers: []string{validDNSServer},
+		Search:      []string{"example.com"},
+		Options:     []string{"ndots:2"},
+	}, conf.DNS)
+	assert.Equal(t, types.DNS{}, (&VPCENIPluginConfig{}).WithDNS(nil, []string{}, nil).DNS)
+}
+
+// TestNewVPCENIPluginConfigForTaskNSSetupIPv6 tests the generated configuration of v4-only and dual-stack ENIs.
+func TestNewVPCENIPluginConfigForTaskNSSetupIPv6(t *testing.T) {
+	cniConfig := getCNIConfig()
+
+	v4Config, err := NewVPCENIPluginConfigForTaskNSSetup(getTaskENI(), cniConfig)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(v4Config.Bytes), "eniIPV6Address")
+	assert.NotContains(t, string(v4Config.Bytes), "gatewayIPV6Address")
+
+	dualStackENI := getTaskENI()
+	dualStackENI.IPV6Addresses = []*ni.IPV6Address{{Address: ipv6}}
+	dualStackConfig, err := NewVPCENIPluginConfigForTaskNSSetup(dualStackENI, cniConfig)
+	assert.NoError(t, err)
+	assert.Contains(t, string(dualStackConfig.Bytes), `"eniIPV6Address":"`+ipv6CIDR+`"`)
+	assert.Contains(t, string(dualStackConfig.Bytes), `"gatewayIPV6Address":"`+ipv6Gateway+`"`)
+
+	netConfig := &VPCENIPluginConfig{}
+	assert.NoError(t, json.Unmarshal(dualStackConfig.Bytes, netConfig))
+	assert.EqualValues(t, []string{ipv4CIDR}, netConfig.ENIIPAddresses)
+	assert.EqualValues(t, ipv6CIDR, netConfig.ENIIPV6Address)
+	assert.EqualValues(t, ipv6Gateway, netConfig.GatewayIPV6Address)
+}
+
+func TestNewVPCENIPluginConfigForTaskNSSetupInvalidIPv6(t *testing.T) {
+	taskENI := getTaskENI()
+	taskENI.IPV6Addresses = []*ni.IPV6Address{{Address: "10.0.0.121"}}
+
+	config, err := NewVPCENIPluginConfigForTaskNSSetup(taskENI, getCNIConfig())
+
+	assert.Nil(t, config)
+	assert.Error(t, err)
+}
+
 func TestNewVPCENIPluginConfigForTaskNSSetupFailure(t *testing.T) {
 	cniConfig := getCNIConfig()
 	taskENI := getTaskENI()
@@ -94,6 +180,18 @@ func TestNewVPCENIPluginConfigForTaskNSSetupFailure(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestNewVPCENIPluginConfigForTaskNSSetupNormalizesMAC(t *testing.T) {
+	taskENI := getTaskENI()
+	taskENI.MacAddress = "02-7B-64-49-B1-40"
+
+	config, err := NewVPCENIPluginConfigForTaskNSSetup(taskENI, getCNIConfig())
+	require.NoError(t, err)
+
+	netConfig := &VPCENIPluginConfig{}
+	require.NoError(t, json.Unmarshal(config.Bytes, netConfig))
+	assert.Equal(t, mac, netConfig.ENIMACAddress)
+}
+
 // TestNewVPCENIPluginConfigForECSBridgeSetup tests the generated configuration for ecs-bridge setup.
 func TestNewVPCENIPluginConfigForECSBridgeSetup(t *testing.T) {
 	cniConfig := getCNIConfig()
@@ -109,3 +207,74 @@ func TestNewVPCENIPluginConfigForECSBridgeSetup(t *testing.T) {
 	assert.True(t, netConfig.UseExistingNetwork)
 	assert.EqualValues(t, cniConfig.BlockInstanceMetadata, netConfig.BlockIMDS)
 }
+
+// TestVPCENIPluginConfigValidate tests the validation of incompatible vpc-eni plugin configurations.
+func TestVPCENIPluginConfigValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      VPCENIPluginConfig
+		expectError bool
+	}{
+		{
+			name: "task eni",
+			config: VPCENIPluginConfig{
+				ENIName:            linkName,
+				ENIMACAddress:      mac,
+				ENIIPAddresses:     []string{ipv4CIDR},
+				GatewayIPAddresses: []string{validVPCGatewayIPv4Addr},
+				ENIIPV6Address:     ipv6CIDR,
+				GatewayIPV6Address: ipv6Gateway,
+			},
+		},
+		{
+			name:   "existing network",
+			config: VPCENIPluginConfig{UseExistingNetwork: true},
+		},
+		{
+			name: "existing network with eni",
+			config: VPCENIPluginConfig{
+				UseExistingNetwork: true,
+				ENIName:            linkName,
+			},
+			expectError: true,
+		},
+		{
+			name: "missing eni name",
+			config: VPCENIPluginConfig{
+				ENIMACAddress:      mac,
+				ENIIPAddresses:     []string{ipv4CIDR},
+				GatewayIPAddresses: []string{validVPCGatewayIPv4Addr},
+			},
+			expectError: true,
+		},
+		{
+			name: "missing gateway ip address",
+			config: VPCENIPluginConfig{
+				ENIName:        linkName,
+				ENIIPAddresses: []string{ipv4CIDR},
+			},
+			expectError: true,
+		},
+		{
+			name: "missing gateway ipv6 address",
+			config: VPCENIPluginConfig{
+				ENIName:            linkName,
+				ENIIPAddresses:     []string{ipv4CIDR},
+				GatewayIPAddresses: []string{validVPCGatewayIPv4Addr},
+				ENIIPV6Address:     ipv6CIDR,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
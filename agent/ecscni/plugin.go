@@ -24,7 +24,9 @@ import (
 
 	"github.com/cihub/seelog"
 	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/invoke"
 	cniTypesCurrent "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/version"
 	"github.com/pkg/errors"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
@@ -53,11 +55,45 @@ type cniGuard interface {
 	unlock()
 }
 
+// PluginPathResolver returns the absolute path of the executable of the named CNI plugin.
+type PluginPathResolver func(plugin string) (string, error)
+
+// NewPluginPathResolver returns a PluginPathResolver that locates the plugins listed in
+// pluginPaths at their given paths, and all other plugins in pluginsPath. It returns nil,
+// i.e. the default resolution, when pluginPaths is empty.
+func NewPluginPathResolver(pluginsPath string, pluginPaths map[string]string) PluginPathResolver {
+	if len(pluginPaths) == 0 {
+		return nil
+	}
+	return func(plugin string) (string, error) {
+		if pluginPath, ok := pluginPaths[plugin]; ok {
+			return pluginPath, nil
+		}
+		return filepath.Join(pluginsPath, plugin), nil
+	}
+}
+
+// pluginVersionInfoGetter returns the cni spec versions supported by the named plugin.
+type pluginVersionInfoGetter func(ctx context.Context, plugin string) (version.PluginInfo, error)
+
 // cniClient is the client to call plugin and setup the network
 type cniClient struct {
-	pluginsPath string
-	libcni      libcni.CNI
-	guard       cniGuard
+	pluginsPath        string
+	pluginPathResolver PluginPathResolver
+	pluginVersionInfo  pluginVersionInfoGetter
+	libcni             libcni.CNI
+	guard              cniGuard
+	// negotiatedCNIVersions caches the cni spec version negotiated with each plugin, so that
+	// every invocation of a plugin uses the same version.
+	negotiatedCNIVersions     map[cniVersionKey]string
+	negotiatedCNIVersionsLock sync.Mutex
+}
+
+// cniVersionKey identifies a negotiated cni spec version by the plugin and the version it
+// was configured with.
+type cniVersionKey struct {
+	plugin            string
+	configuredVersion string
 }
 
 // guard is the client to call lock and unlock methods on the mutex.
@@ -67,19 +103,56 @@ type guard struct {
 
 // NewClient creates a client of ecscni which is used to invoke the plugin
 func NewClient(pluginsPath string) CNIClient {
-	libcniConfig := &libcni.CNIConfig{
-		Path: []string{pluginsPath},
+	return NewClientWithPluginPathResolver(pluginsPath, nil)
+}
+
+// NewClientWithPluginPathResolver creates a client of ecscni which uses the given resolver
+// to locate the plugin executables. When the resolver is nil, plugins are looked up in
+// pluginsPath.
+func NewClientWithPluginPathResolver(pluginsPath string, resolver PluginPathResolver) CNIClient {
+	var pluginExec invoke.Exec = &invoke.DefaultExec{
+		RawExec:       &invoke.RawExec{Stderr: os.Stderr},
+		PluginDecoder: version.PluginDecoder{},
+	}
+	if resolver != nil {
+		pluginExec = &resolverExec{Exec: pluginExec, resolve: resolver}
+	} else {
+		resolver = func(plugin string) (string, error) {
+			return filepath.Join(pluginsPath, plugin), nil
+		}
 	}
+	libcniConfig := libcni.NewCNIConfig([]string{pluginsPath}, pluginExec)
 
 	cniClient := &cniClient{
-		pluginsPath: pluginsPath,
-		libcni:      libcniConfig,
-		guard:       newCNIGuard(),
+		pluginsPath:        pluginsPath,
+		pluginPathResolver: resolver,
+		pluginVersionInfo: func(ctx context.Context, plugin string) (version.PluginInfo, error) {
+			file, err := resolver(plugin)
+			if err != nil {
+				return nil, err
+			}
+			return invoke.GetVersionInfo(ctx, file, pluginExec)
+		},
+		libcni:                libcniConfig,
+		guard:                 newCNIGuard(),
+		negotiatedCNIVersions: make(map[cniVersionKey]string),
 	}
 	cniClient.init()
 	return cniClient
 }
 
+// resolverExec is the libcni plugin executor of the client. It locates the plugin
+// executables through the client's resolver instead of searching the plugin paths.
+type resolverExec struct {
+	invoke.Exec
+	resolve PluginPathResolver
+}
+
+// FindInPath returns the path of the plugin executable returned by the resolver.
+func (e *resolverExec) FindInPath(plugin string, _ []string) (string, error) {
+	return e.resolve(plugin)
+}
+
 func (client *cniClient) init() {
 	// Set environment variables for CNI plugins.
 	os.Setenv("ECS_CNI_LOGLEVEL", logger.GetLevel())
@@ -134,7 +207,7 @@ func (client *cniClient) cleanupNS(ctx context.Context, cfg *Config) error {
 			cniNetworkConfig.Network.Type,
 			cfg.ContainerID)
 		runtimeConfig.IfName = networkConfig.IfName
-		err := client.libcni.DelNetwork(ctx, cniNetworkConfig, &runtimeConfig)
+		err := client.libcni.DelNetwork(ctx, client.negotiateCNIVersion(ctx, cniNetworkConfig), &runtimeConfig)
 		if err != nil {
 			// In case of error, continue cleanup as much as possible before conceding error.
 			seelog.Errorf("Delete network failed: %v", err)
@@ -152,12 +225,124 @@ func (client *cniClient) cleanupNS(ctx context.Context, cfg *Config) error {
 	return delError
 }
 
+// negotiatedCNIVersion returns the highest cni spec version supported by both the plugin and
+// the agent. The configured version acts as the minimum and is used if the versions of the
+// plugin can't be queried or no higher version is mutually supported. The version is only
+// negotiated once per plugin and configured version, so that ADD, DEL and CHECK of the same
+// network agree on it. The plugin is queried without holding the lock of the cache.
+func (client *cniClient) negotiatedCNIVersion(ctx context.Context, plugin, configuredVersion string) string {
+	key := cniVersionKey{plugin: plugin, configuredVersion: configuredVersion}
+	client.negotiatedCNIVersionsLock.Lock()
+	negotiatedVersion, ok := client.negotiatedCNIVersions[key]
+	client.negotiatedCNIVersionsLock.Unlock()
+	if ok {
+		return negotiatedVersion
+	}
+
+	negotiatedVersion = configuredVersion
+	info, err := client.pluginVersionInfo(ctx, plugin)
+	if err != nil {
+		seelog.Warnf("[ECSCNI] Unable to negotiate the cni version of plugin %s, using version %s: %v",
+			plugin, configuredVersion, err)
+	} else if highest := highestCommonCNIVersion(info.SupportedVersions(), version.All.SupportedVersions(),
+		configuredVersion); highest != "" {
+		negotiatedVersion = highest
+	}
+
+	client.negotiatedCNIVersionsLock.Lock()
+	defer client.negotiatedCNIVersionsLock.Unlock()
+	// Keep the version of a concurrent negotiation that completed first.
+	if cachedVersion, ok := client.negotiatedCNIVersions[key]; ok {
+		return cachedVersion
+	}
+	client.negotiatedCNIVersions[key] = negotiatedVersion
+	seelog.Debugf("[ECSCNI] Negotiated cni version %s for plugin %s", negotiatedVersion, plugin)
+	return negotiatedVersion
+}
+
+// negotiateCNIVersion returns the network configuration to invoke its plugin with, set to
+// the cni spec version negotiated with the plugin. Configurations of plugins that don't
+// negotiate their version are returned as is.
+func (client *cniClient) negotiateCNIVersion(ctx context.Context,
+	networkConfig *libcni.NetworkConfig) *libcni.NetworkConfig {
+	plugin := networkConfig.Network.Type
+	if !negotiatesCNIVersion(plugin) {
+		return networkConfig
+	}
+	configuredVersion := networkConfig.Network.CNIVersion
+	negotiatedVersion := client.negotiatedCNIVersion(ctx, plugin, configuredVersion)
+	if negotiatedVersion == configuredVersion {
+		return networkConfig
+	}
+
+	versionedConfig, err := withCNIVersion(networkConfig, negotiatedVersion)
+	if err != nil {
+		seelog.Warnf("[ECSCNI] Unable to set the cni version of plugin %s to %s, using version %s: %v",
+			plugin, negotiatedVersion, configuredVersion, err)
+		return networkConfig
+	}
+	return versionedConfig
+}
+
+// highestCommonCNIVersion returns the highest cni spec version that is in both lists of
+// versions and isn't lower than minVersion. It returns an empty string if there's none.
+func highestCommonCNIVersion(pluginVersions, agentVersions []string, minVersion string) string {
+	agentSupported := make(map[string]struct{}, len(agentVersions))
+	for _, agentVersion := range agentVersions {
+		agentSupported[agentVersion] = struct{}{}
+	}
+
+	highest := minVersion
+	found := false
+	for _, pluginVersion := range pluginVersions {
+		if _, ok := agentSupported[pluginVersion]; !ok {
+			continue
+		}
+		if higher, err := version.GreaterThanOrEqualTo(pluginVersion, highest); err == nil && higher {
+			highest = pluginVersion
+			found = true
+		}
+	}
+	if !found {
+		return ""
+	}
+	return highest
+}
+
+// withCNIVersion returns a copy of the network configuration set to the given cni spec
+// version. The other fields of the plugin configuration are left untouched.
+func withCNIVersion(networkConfig *libcni.NetworkConfig, cniVersion string) (*libcni.NetworkConfig, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(networkConfig.Bytes, &fields); err != nil {
+		return nil, errors.Wrap(err, "ecscni: unable to unmarshal network configuration")
+	}
+	versionBytes, err := json.Marshal(cniVersion)
+	if err != nil {
+		return nil, err
+	}
+	fields["cniVersion"] = versionBytes
+	configBytes, err := json.Marshal(fields)
+	if err != nil {
+		return nil, errors.Wrap(err, "ecscni: unable to marshal network configuration")
+	}
+
+	network := *networkConfig.Network
+	network.CNIVersion = cniVersion
+	return &libcni.NetworkConfig{
+		Network: &network,
+		Bytes:   configBytes,
+	}, nil
+}
+
 // Version returns the version of the plugin
 func (client *cniClient) Version(name string) (string, error) {
-	file := filepath.Join(client.pluginsPath, name)
+	file, err := client.pluginPathResolver(name)
+	if err != nil {
+		return "", errors.Wrapf(err, "ecscni: unable to resolve path of plugin '%s'", name)
+	}
 
 	// Check if the plugin file exists before executing it
-	_, err := os.Stat(file)
+	_, err = os.Stat(file)
 	if err != nil {
 		return "", err
 	}
@@ -184,10 +369,13 @@ func (client *cniClient) Version(name string) (string, error) {
 
 // Capabilities returns the capabilities supported by a plugin
 func (client *cniClient) Capabilities(name string) ([]string, error) {
-	file := filepath.Join(client.pluginsPath, name)
+	file, err := client.pluginPathResolver(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "ecscni: unable to resolve path of plugin '%s'", name)
+	}
 
 	// Check if the plugin file exists before executing it
-	_, err := os.Stat(file)
+	_, err = os.Stat(file)
 	if err != nil {
 		return nil, errors.Wrapf(err, "ecscni: unable to describe file info for '%s'", file)
 	}
@@ -45,6 +45,12 @@ func newCNIGuard() cniGuard {
 	}
 }
 
+// negotiatesCNIVersion returns true if the cni spec version of the plugin is negotiated
+// before invoking it. Only the vpc-eni plugin negotiates its version.
+func negotiatesCNIVersion(plugin string) bool {
+	return plugin == VPCENIPluginName
+}
+
 // setupNS is the called by SetupNS to setup the task namespace by invoking ADD for given CNI configurations
 func (client *cniClient) setupNS(ctx context.Context, cfg *Config) (*cniTypesCurrent.Result, error) {
 	seelog.Debugf("[ECSCNI] Setting up the container namespace %s", cfg.ContainerID)
@@ -57,7 +63,7 @@ func (client *cniClient) setupNS(ctx context.Context, cfg *Config) (*cniTypesCur
 
 	// Execute all CNI network configurations serially, in the given order.
 	for _, networkConfig := range cfg.NetworkConfigs {
-		cniNetworkConfig := networkConfig.CNINetworkConfig
+		cniNetworkConfig := client.negotiateCNIVersion(ctx, networkConfig.CNINetworkConfig)
 		seelog.Debugf("[ECSCNI] Adding network %s type %s in the container namespace %s",
 			cniNetworkConfig.Network.Name,
 			cniNetworkConfig.Network.Type,
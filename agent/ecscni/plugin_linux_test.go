@@ -32,6 +32,7 @@ import (
 	"github.com/containernetworking/cni/libcni"
 	cniTypes "github.com/containernetworking/cni/pkg/types"
 	cniTypesCurrent "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/version"
 
 	"github.com/aws/amazon-ecs-agent/agent/api/serviceconnect"
 	mock_libcni "github.com/aws/amazon-ecs-agent/agent/ecscni/mocks_libcni"
@@ -361,6 +362,39 @@ func TestCleanupNS(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// TestSetupAndCleanupNSNegotiatedCNIVersion tests that ADD and DEL of the vpc-eni plugin use the same
+// negotiated cni version, and that the version is only negotiated once.
+func TestSetupAndCleanupNSNegotiatedCNIVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ecscniClient := NewClient("")
+	libcniClient := mock_libcni.NewMockCNI(ctrl)
+	ecscniClient.(*cniClient).libcni = libcniClient
+	queries := 0
+	ecscniClient.(*cniClient).pluginVersionInfo = func(ctx context.Context, plugin string) (version.PluginInfo, error) {
+		queries++
+		return version.PluginSupports("0.3.0", "0.4.0"), nil
+	}
+
+	libcniClient.EXPECT().AddNetwork(gomock.Any(), gomock.Any(), gomock.Any()).Return(&cniTypesCurrent.Result{}, nil).Do(
+		func(ctx context.Context, net *libcni.NetworkConfig, rt *libcni.RuntimeConf) {
+			assert.Equal(t, "0.4.0", net.Network.CNIVersion)
+		})
+	libcniClient.EXPECT().DelNetwork(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Do(
+		func(ctx context.Context, net *libcni.NetworkConfig, rt *libcni.RuntimeConf) {
+			assert.Equal(t, "0.4.0", net.Network.CNIVersion)
+		})
+
+	config := &Config{MinSupportedCNIVersion: "0.3.0"}
+	config.NetworkConfigs = []*NetworkConfig{eniNetworkConfig(config)}
+	_, err := ecscniClient.SetupNS(context.TODO(), config, time.Second)
+	require.NoError(t, err)
+	err = ecscniClient.CleanupNS(context.TODO(), config, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 1, queries)
+}
+
 func TestCleanupNSTrunk(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -520,6 +554,7 @@ func TestConstructVPCENINetworkConfig(t *testing.T) {
 	require.NoError(t, err, "unmarshal config from bytes failed")
 	assert.Equal(t, &VPCENIPluginConfig{
 		Type:               "vpc-eni",
+		CNIVersion:         "0.3.0",
 		ENIIPAddresses:     []string{eniIPV4AddressWithBlockSize, eniIPV6AddressWithBlockSize},
 		ENIMACAddress:      eniMACAddress,
 		BlockIMDS:          true,
@@ -17,13 +17,20 @@
 package ecscni
 
 import (
+	"context"
+	"encoding/json"
 	"io/ioutil"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/version"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -31,6 +38,8 @@ const (
 	currentECSCNIVersion = "2020.09.0"
 	currentECSCNIGitHash = "53a8481891251e66e35847554d52a13fc7c4fd03"
 	currentVPCCNIGitHash = "be5214353252f8315a1341f4df9ffbd8cf69000c"
+
+	testPluginName = "vpc-eni"
 )
 
 // Asserts that CNI plugin version matches the expected version
@@ -53,6 +62,132 @@ func TestCNIPluginVersionUpgrade(t *testing.T) {
 	assert.Equal(t, currentVPCCNIGitHash, strings.Split(versionInfoStrList[1], " ")[1])
 }
 
+// Asserts that a custom plugin path resolver overrides the plugins path of the client
+func TestCNIClientCustomPluginPathResolver(t *testing.T) {
+	customPath := filepath.Join(t.TempDir(), "custom", testPluginName)
+	var resolved []string
+	resolver := func(plugin string) (string, error) {
+		resolved = append(resolved, plugin)
+		return customPath, nil
+	}
+	client := NewClientWithPluginPathResolver(t.TempDir(), resolver).(*cniClient)
+
+	_, err := client.Version(testPluginName)
+	require.Error(t, err)
+	assert.True(t, os.IsNotExist(errors.Cause(err)))
+	assert.Contains(t, err.Error(), customPath)
+
+	_, err = client.Capabilities(testPluginName)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), customPath)
+
+	_, err = client.libcni.(*libcni.CNIConfig).GetVersionInfo(context.TODO(), testPluginName)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), customPath)
+
+	assert.Equal(t, []string{testPluginName, testPluginName, testPluginName}, resolved)
+}
+
+// Asserts that the client looks up plugins in its plugins path by default
+func TestCNIClientDefaultPluginPathResolver(t *testing.T) {
+	pluginsPath := t.TempDir()
+	client := NewClient(pluginsPath).(*cniClient)
+
+	pluginPath, err := client.pluginPathResolver(testPluginName)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(pluginsPath, testPluginName), pluginPath)
+}
+
+// Asserts that the plugin path resolver built from the configured plugin paths overrides
+// the plugins path only for the configured plugins
+func TestNewPluginPathResolver(t *testing.T) {
+	pluginsPath := t.TempDir()
+	assert.Nil(t, NewPluginPathResolver(pluginsPath, nil))
+
+	customPath := filepath.Join(t.TempDir(), "custom", testPluginName)
+	resolver := NewPluginPathResolver(pluginsPath, map[string]string{testPluginName: customPath})
+	pluginPath, err := resolver(testPluginName)
+	require.NoError(t, err)
+	assert.Equal(t, customPath, pluginPath)
+
+	pluginPath, err = resolver("ecs-bridge")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(pluginsPath, "ecs-bridge"), pluginPath)
+}
+
+// Asserts that the cni version of a plugin is negotiated from the versions reported by the
+// plugin, falling back to the configured version, and that it's only negotiated once
+func TestNegotiatedCNIVersion(t *testing.T) {
+	testCases := []struct {
+		name            string
+		pluginVersions  version.PluginInfo
+		versionErr      error
+		expectedVersion string
+	}{
+		{
+			name:            "highest mutually supported version",
+			pluginVersions:  version.PluginSupports("0.3.0", "0.3.1", "0.4.0", "1.0.0", "1.1.0"),
+			expectedVersion: "1.0.0",
+		},
+		{
+			name:            "only lower versions supported by the plugin",
+			pluginVersions:  version.PluginSupports("0.1.0", "0.2.0"),
+			expectedVersion: "0.3.0",
+		},
+		{
+			name:            "version query failure",
+			versionErr:      errors.New("exec: vpc-eni not found"),
+			expectedVersion: "0.3.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewClient(t.TempDir()).(*cniClient)
+			queries := 0
+			client.pluginVersionInfo = func(ctx context.Context, plugin string) (version.PluginInfo, error) {
+				queries++
+				assert.Equal(t, testPluginName, plugin)
+				return tc.pluginVersions, tc.versionErr
+			}
+
+			assert.Equal(t, tc.expectedVersion, client.negotiatedCNIVersion(context.TODO(), testPluginName, "0.3.0"))
+			// The version stays the same for later invocations, even if the query failed
+			client.pluginVersionInfo = func(ctx context.Context, plugin string) (version.PluginInfo, error) {
+				queries++
+				return version.PluginSupports("0.3.0", "0.4.0"), nil
+			}
+			assert.Equal(t, tc.expectedVersion, client.negotiatedCNIVersion(context.TODO(), testPluginName, "0.3.0"))
+			assert.Equal(t, 1, queries, "negotiated version should be cached")
+		})
+	}
+}
+
+// Asserts that setting the cni version of a network configuration leaves the rest of the
+// configuration untouched
+func TestWithCNIVersion(t *testing.T) {
+	eniConf, err := NewVPCENIPluginConfigBuilder().
+		WithCNIVersion("0.3.0").
+		WithENI("eth1", "02:7b:64:49:b1:40", []string{"10.0.0.5/24"}).
+		WithGateway([]string{"10.0.0.1"}).
+		Build()
+	require.NoError(t, err)
+	networkConfig, err := newNetworkConfig(eniConf, testPluginName, "0.3.0")
+	require.NoError(t, err)
+
+	versioned, err := withCNIVersion(networkConfig, "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", versioned.Network.CNIVersion)
+	assert.Equal(t, networkConfig.Network.Name, versioned.Network.Name)
+	var versionedConf VPCENIPluginConfig
+	require.NoError(t, json.Unmarshal(versioned.Bytes, &versionedConf))
+	assert.Equal(t, "1.0.0", versionedConf.CNIVersion)
+	assert.Equal(t, eniConf.ENIMACAddress, versionedConf.ENIMACAddress)
+	assert.Equal(t, eniConf.GatewayIPAddresses, versionedConf.GatewayIPAddresses)
+	// The configuration passed in is left untouched
+	assert.Equal(t, "0.3.0", networkConfig.Network.CNIVersion)
+}
+
 // Returns the version in CNI plugin VERSION file as a string
 func getCNIVersionString(t *testing.T) string {
 	// ../../amazon-ecs-cni-plugins/VERSION
@@ -37,6 +37,12 @@ func newCNIGuard() cniGuard {
 	}
 }
 
+// negotiatesCNIVersion returns true if the cni spec version of the plugin is negotiated
+// before invoking it. It is not supported on this platform.
+func negotiatesCNIVersion(plugin string) bool {
+	return false
+}
+
 type cniPluginVersion struct{}
 
 // setupNS is the called by SetupNS to setup the task namespace by invoking ADD for given CNI configurations
@@ -18,6 +18,9 @@ package ecscni
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
@@ -39,6 +42,9 @@ var (
 	// The default value of log file is C:\ProgramData\Amazon\ECS\log\cni\vpc-eni.log
 	vpcCNIPluginPath = filepath.Join(utils.DefaultIfBlank(os.Getenv("ProgramData"), `C:\ProgramData`),
 		`Amazon\ECS\log\cni\vpc-eni.log`)
+	// getAdapterAddresses returns the IP addresses of the network adapters with the given
+	// MAC address. It can be overridden in unit tests.
+	getAdapterAddresses = adapterAddresses
 )
 
 // newCNIGuard returns a new instance of CNI guard for the CNI client.
@@ -48,27 +54,110 @@ func newCNIGuard() cniGuard {
 	}
 }
 
+// negotiatesCNIVersion returns true if the cni spec version of the plugin is negotiated
+// before invoking it. Only the vpc-eni plugin negotiates its version.
+func negotiatesCNIVersion(plugin string) bool {
+	return plugin == ECSVPCENIPluginExecutable
+}
+
 // setupNS is the called by SetupNS to setup the task namespace by invoking ADD for given CNI configurations.
-// For Windows, we will retry the setup before conceding error.
+// For Windows, we will retry the setup before conceding error. The first attempt is
+// delayed by up to the initial delay of the retry config, if any. The retries are abandoned as soon as the
+// context is cancelled, or once the setup timeout of the retry config, if any, is exceeded.
 func (client *cniClient) setupNS(ctx context.Context, cfg *Config) (*cniTypesCurrent.Result, error) {
 	var result *cniTypesCurrent.Result
 	var err error
-	backoff := retry.NewExponentialBackoff(setupNSBackoffMin, setupNSBackoffMax,
-		setupNSBackoffJitter, setupNSBackoffMultiple)
+	retryConfig := getSetupNSRetryConfig(cfg.SetupNSRetryConfig)
+	backoff := retry.NewExponentialBackoff(retryConfig.BackoffMin, retryConfig.BackoffMax,
+		retryConfig.BackoffJitter, retryConfig.BackoffMultiple)
+
+	setupCtx := ctx
+	if retryConfig.SetupTimeout > 0 {
+		var cancel context.CancelFunc
+		setupCtx, cancel = context.WithTimeout(ctx, retryConfig.SetupTimeout)
+		defer cancel()
+	}
 
-	for count := 0; count < setupNSMaxRetryCount; count++ {
-		result, err = client.doSetupNS(ctx, cfg)
+	if retryConfig.InitialDelayMax > 0 {
+		select {
+		case <-setupCtx.Done():
+			if setupTimedOut(ctx, setupCtx) {
+				return nil, errors.Wrapf(setupCtx.Err(), "namespace setup timed out after %s",
+					retryConfig.SetupTimeout)
+			}
+			return nil, errors.Wrap(ctx.Err(), "namespace setup abandoned before the first attempt")
+		case <-time.After(retry.AddJitter(0, retryConfig.InitialDelayMax)):
+		}
+	}
+
+	for count := 0; count < retryConfig.MaxRetryCount; count++ {
+		result, err = client.doSetupNS(setupCtx, cfg)
 		if err == nil {
 			return result, nil
 		}
-		if count < setupNSMaxRetryCount-1 {
-			time.Sleep(backoff.Duration())
-		}
 		seelog.Errorf("[ECSCNI] Namespace setup failed due to error: %v. Retry count is %d.", err, count)
+		if setupTimedOut(ctx, setupCtx) {
+			return nil, errors.Wrapf(setupCtx.Err(), "namespace setup timed out after %s: %v",
+				retryConfig.SetupTimeout, err)
+		}
+		if count < retryConfig.MaxRetryCount-1 {
+			select {
+			case <-setupCtx.Done():
+				if setupTimedOut(ctx, setupCtx) {
+					return nil, errors.Wrapf(setupCtx.Err(), "namespace setup timed out after %s: %v",
+						retryConfig.SetupTimeout, err)
+				}
+				return nil, errors.Wrapf(ctx.Err(), "namespace setup abandoned after %d attempts", count+1)
+			case <-time.After(backoff.Duration()):
+			}
+		}
 	}
 	return nil, err
 }
 
+// setupTimedOut returns true if setupCtx, derived from ctx, has expired because of the
+// setup timeout rather than because ctx itself is done.
+func setupTimedOut(ctx, setupCtx context.Context) bool {
+	return setupCtx != ctx && setupCtx.Err() != nil && ctx.Err() == nil
+}
+
+// getSetupNSRetryConfig returns the retry parameters for setupNS, using the defaults
+// for any parameter which has not been overridden.
+func getSetupNSRetryConfig(override *SetupNSRetryConfig) SetupNSRetryConfig {
+	retryConfig := SetupNSRetryConfig{
+		BackoffMin:      setupNSBackoffMin,
+		BackoffMax:      setupNSBackoffMax,
+		BackoffJitter:   setupNSBackoffJitter,
+		BackoffMultiple: setupNSBackoffMultiple,
+		MaxRetryCount:   setupNSMaxRetryCount,
+	}
+	if override == nil {
+		return retryConfig
+	}
+	if override.BackoffMin > 0 {
+		retryConfig.BackoffMin = override.BackoffMin
+	}
+	if override.BackoffMax > 0 {
+		retryConfig.BackoffMax = override.BackoffMax
+	}
+	if override.BackoffJitter > 0 {
+		retryConfig.BackoffJitter = override.BackoffJitter
+	}
+	if override.BackoffMultiple > 0 {
+		retryConfig.BackoffMultiple = override.BackoffMultiple
+	}
+	if override.MaxRetryCount > 0 {
+		retryConfig.MaxRetryCount = override.MaxRetryCount
+	}
+	if override.SetupTimeout > 0 {
+		retryConfig.SetupTimeout = override.SetupTimeout
+	}
+	if override.InitialDelayMax > 0 {
+		retryConfig.InitialDelayMax = override.InitialDelayMax
+	}
+	return retryConfig
+}
+
 // doSetupNS invokes the CNI plugins to setup the task network namespace.
 func (client *cniClient) doSetupNS(ctx context.Context, cfg *Config) (*cniTypesCurrent.Result, error) {
 	seelog.Debugf("[ECSCNI] Setting up the container namespace %s", cfg.ContainerID)
@@ -80,8 +169,8 @@ func (client *cniClient) doSetupNS(ctx context.Context, cfg *Config) (*cniTypesC
 	}
 
 	// Execute all CNI network configurations serially, in the given order.
-	for _, networkConfig := range cfg.NetworkConfigs {
-		cniNetworkConfig := networkConfig.CNINetworkConfig
+	for i, networkConfig := range cfg.NetworkConfigs {
+		cniNetworkConfig := client.negotiateCNIVersion(ctx, networkConfig.CNINetworkConfig)
 		seelog.Debugf("[ECSCNI] Adding network %s type %s in the container namespace %s",
 			cniNetworkConfig.Network.Name,
 			cniNetworkConfig.Network.Type,
@@ -89,8 +178,18 @@ func (client *cniClient) doSetupNS(ctx context.Context, cfg *Config) (*cniTypesC
 		runtimeConfig.IfName = networkConfig.IfName
 		result, err := client.libcni.AddNetwork(ctx, cniNetworkConfig, &runtimeConfig)
 		if err != nil {
+			// Tear down what this attempt created, including any endpoint the failed
+			// invocation may have left behind, so that a retry starts from a clean slate.
+			client.cleanupFailedSetupNS(cfg, cfg.NetworkConfigs[:i+1])
 			return nil, errors.Wrap(err, "add network failed")
 		}
+		if cfg.VerifyENISetup && cniNetworkConfig.Network.Type == ECSVPCENIPluginExecutable {
+			if err := verifyENISetup(ctx, cniNetworkConfig); err != nil {
+				// The plugin reported success without fully setting up the ENI.
+				client.cleanupFailedSetupNS(cfg, cfg.NetworkConfigs[:i+1])
+				return nil, errors.Wrap(err, "eni verification failed")
+			}
+		}
 
 		// We save the result from ecs-bridge setup invocation of the plugin.
 		if strings.EqualFold(ECSBridgeNetworkName, cniNetworkConfig.Network.Name) &&
@@ -109,6 +208,128 @@ func (client *cniClient) doSetupNS(ctx context.Context, cfg *Config) (*cniTypesC
 	return cniTypesCurrent.GetResult(ecsBridgeResult)
 }
 
+// verifyENISetup checks that the adapter of the task ENI set up by the vpc-eni plugin with the
+// given configuration has been assigned the configured IPv4 addresses, and IPv6 address if any.
+// The check is retried briefly as the addresses may take some time to show up. Configurations
+// of endpoints in existing networks, which don't set up an ENI, aren't verified.
+func verifyENISetup(ctx context.Context, networkConfig *libcni.NetworkConfig) error {
+	var eniConf VPCENIPluginConfig
+	if err := json.Unmarshal(networkConfig.Bytes, &eniConf); err != nil {
+		return errors.Wrap(err, "unable to unmarshal vpc-eni plugin configuration")
+	}
+	if eniConf.UseExistingNetwork || eniConf.ENIMACAddress == "" {
+		return nil
+	}
+
+	expected := append([]string{}, eniConf.ENIIPAddresses...)
+	if eniConf.ENIIPV6Address != "" {
+		expected = append(expected, eniConf.ENIIPV6Address)
+	}
+	var err error
+	for attempt := 1; ; attempt++ {
+		var addresses []net.IP
+		addresses, err = getAdapterAddresses(eniConf.ENIMACAddress)
+		if err == nil {
+			missing := missingAddresses(expected, addresses)
+			if len(missing) == 0 {
+				return nil
+			}
+			err = errors.Errorf("adapter %s is missing addresses %v, has %v",
+				eniConf.ENIMACAddress, missing, addresses)
+		}
+		if attempt >= eniVerificationMaxAttempts {
+			return err
+		}
+		seelog.Warnf("[ECSCNI] ENI verification attempt %d failed: %v", attempt, err)
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "eni verification abandoned: %v", err)
+		case <-time.After(eniVerificationInterval):
+		}
+	}
+}
+
+// missingAddresses returns the expected addresses, in the address or CIDR notation, which
+// aren't among the given addresses.
+func missingAddresses(expected []string, addresses []net.IP) []string {
+	var missing []string
+	for _, address := range expected {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			ip, _, _ = net.ParseCIDR(address)
+		}
+		found := false
+		for _, assigned := range addresses {
+			if ip != nil && ip.Equal(assigned) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, address)
+		}
+	}
+	return missing
+}
+
+// adapterAddresses returns the IP addresses of the network adapters with the given MAC
+// address. It fails if there is no such adapter.
+func adapterAddresses(macAddress string) ([]net.IP, error) {
+	mac, err := NormalizeMAC(macAddress)
+	if err != nil {
+		return nil, err
+	}
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list network adapters")
+	}
+
+	var addresses []net.IP
+	found := false
+	for _, iface := range interfaces {
+		if iface.HardwareAddr.String() != mac {
+			continue
+		}
+		found = true
+		ifaceAddresses, err := iface.Addrs()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to list addresses of network adapter %s", iface.Name)
+		}
+		for _, address := range ifaceAddresses {
+			if ipNet, ok := address.(*net.IPNet); ok {
+				addresses = append(addresses, ipNet.IP)
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no network adapter found with mac address %s", mac)
+	}
+	return addresses, nil
+}
+
+// cleanupFailedSetupNS invokes DEL, in the reverse order, for the given network configurations
+// of a failed attempt to set up the task namespace. The cleanup is best-effort: errors, such as
+// the endpoint being already gone, are logged and ignored. It doesn't use the context of the
+// setup so that it still runs once the setup has been abandoned.
+func (client *cniClient) cleanupFailedSetupNS(cfg *Config, networkConfigs []*NetworkConfig) {
+	ctx, cancel := context.WithTimeout(context.Background(), setupNSCleanupTimeout)
+	defer cancel()
+
+	runtimeConfig := libcni.RuntimeConf{
+		ContainerID: cfg.ContainerID,
+		NetNS:       cfg.ContainerNetNS,
+	}
+	for i := len(networkConfigs) - 1; i >= 0; i-- {
+		cniNetworkConfig := client.negotiateCNIVersion(ctx, networkConfigs[i].CNINetworkConfig)
+		runtimeConfig.IfName = networkConfigs[i].IfName
+		err := client.libcni.DelNetwork(ctx, cniNetworkConfig, &runtimeConfig)
+		if err != nil {
+			seelog.Warnf("[ECSCNI] Unable to clean up network %s type %s after failed setup of the container namespace %s: %v",
+				cniNetworkConfig.Network.Name, cniNetworkConfig.Network.Type, cfg.ContainerID, err)
+		}
+	}
+}
+
 // ReleaseIPResource marks the ip available in the ipam db
 // This method is not required in Windows. HNS takes care of IP management.
 func (client *cniClient) ReleaseIPResource(ctx context.Context, cfg *Config, timeout time.Duration) error {
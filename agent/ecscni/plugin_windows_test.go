@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"strings"
 	"testing"
 	"time"
@@ -32,6 +33,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/containernetworking/cni/libcni"
+	cniTypes "github.com/containernetworking/cni/pkg/types"
 	cniTypesCurrent "github.com/containernetworking/cni/pkg/types/100"
 
 	mock_libcni "github.com/aws/amazon-ecs-agent/agent/ecscni/mocks_libcni"
@@ -120,6 +122,8 @@ func TestSetupNSTimeout(t *testing.T) {
 			func(ctx context.Context, net *libcni.NetworkConfig, rt *libcni.RuntimeConf) {
 			}).MaxTimes(setupNSMaxRetryCount),
 	)
+	// The failed attempts will be cleaned up.
+	libcniClient.EXPECT().DelNetwork(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).MaxTimes(setupNSMaxRetryCount)
 
 	config := getNetworkConfig()
 	_, err := ecscniClient.SetupNS(context.TODO(), config, time.Millisecond)
@@ -141,6 +145,8 @@ func TestSetupNSWithRetry(t *testing.T) {
 		libcniClient.EXPECT().AddNetwork(gomock.Any(), gomock.Any(), gomock.Any()).Return(&cniTypesCurrent.Result{}, errors.New("timeout")).Do(
 			func(ctx context.Context, net *libcni.NetworkConfig, rt *libcni.RuntimeConf) {
 			}),
+		// The failed attempt will be cleaned up before retrying.
+		libcniClient.EXPECT().DelNetwork(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
 		libcniClient.EXPECT().AddNetwork(gomock.Any(), gomock.Any(), gomock.Any()).Return(&cniTypesCurrent.Result{}, nil).Do(
 			func(ctx context.Context, net *libcni.NetworkConfig, rt *libcni.RuntimeConf) {
 				assert.Equal(t, ECSVPCENIPluginExecutable, net.Network.Type, "first plugin should be vpc-eni")
@@ -153,6 +159,303 @@ func TestSetupNSWithRetry(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// TestSetupNSWithRetryConfig tests that the retry count provided in the config is honored.
+func TestSetupNSWithRetryConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ecscniClient := NewClient("")
+	libcniClient := mock_libcni.NewMockCNI(ctrl)
+	ecscniClient.(*cniClient).libcni = libcniClient
+
+	gomock.InOrder(
+		// The first setupNS attempts will fail, beyond the default retry count, and the last one will succeed.
+		libcniClient.EXPECT().AddNetwork(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&cniTypesCurrent.Result{}, errors.New("timeout")).Times(setupNSMaxRetryCount),
+		libcniClient.EXPECT().AddNetwork(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&cniTypesCurrent.Result{}, nil).Times(2),
+	)
+	libcniClient.EXPECT().DelNetwork(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(setupNSMaxRetryCount)
+
+	config := getNetworkConfig()
+	config.SetupNSRetryConfig = &SetupNSRetryConfig{
+		BackoffMin:    time.Millisecond,
+		BackoffMax:    time.Millisecond,
+		MaxRetryCount: setupNSMaxRetryCount + 1,
+	}
+	_, err := ecscniClient.SetupNS(context.TODO(), config, time.Second)
+
+	assert.NoError(t, err)
+}
+
+// TestSetupNSWithRetryConfigExhausted tests that setupNS gives up after the configured retry count.
+func TestSetupNSWithRetryConfigExhausted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ecscniClient := NewClient("")
+	libcniClient := mock_libcni.NewMockCNI(ctrl)
+	ecscniClient.(*cniClient).libcni = libcniClient
+
+	libcniClient.EXPECT().AddNetwork(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&cniTypesCurrent.Result{}, errors.New("timeout")).Times(2)
+	libcniClient.EXPECT().DelNetwork(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	config := getNetworkConfig()
+	config.SetupNSRetryConfig = &SetupNSRetryConfig{
+		BackoffMin:    time.Millisecond,
+		BackoffMax:    time.Millisecond,
+		MaxRetryCount: 2,
+	}
+	_, err := ecscniClient.SetupNS(context.TODO(), config, time.Second)
+
+	assert.Error(t, err)
+}
+
+// TestSetupNSInitialDelay tests that the first setupNS attempt is delayed within the
+// configured bound when the initial delay is enabled.
+func TestSetupNSInitialDelay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ecscniClient := NewClient("")
+	libcniClient := mock_libcni.NewMockCNI(ctrl)
+	ecscniClient.(*cniClient).libcni = libcniClient
+
+	initialDelayMax := 200 * time.Millisecond
+	start := time.Now()
+	var firstAttempt time.Time
+	libcniClient.EXPECT().AddNetwork(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_, _, _ interface{}) (cniTypes.Result, error) {
+			if firstAttempt.IsZero() {
+				firstAttempt = time.Now()
+			}
+			return &cniTypesCurrent.Result{}, nil
+		}).Times(2)
+
+	config := getNetworkConfig()
+	config.SetupNSRetryConfig = &SetupNSRetryConfig{InitialDelayMax: initialDelayMax}
+	_, err := ecscniClient.SetupNS(context.TODO(), config, time.Second)
+
+	require.NoError(t, err)
+	// Allow for the scheduling overhead on top of the bound.
+	assert.Less(t, firstAttempt.Sub(start), initialDelayMax+100*time.Millisecond)
+}
+
+// TestSetupNSInitialDelayCancelled tests that setupNS doesn't make any attempt when the
+// context is cancelled during the initial delay.
+func TestSetupNSInitialDelayCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ecscniClient := NewClient("")
+	libcniClient := mock_libcni.NewMockCNI(ctrl)
+	ecscniClient.(*cniClient).libcni = libcniClient
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	config := getNetworkConfig()
+	config.SetupNSRetryConfig = &SetupNSRetryConfig{InitialDelayMax: time.Minute}
+	_, err := ecscniClient.SetupNS(ctx, config, time.Second)
+
+	assert.Error(t, err)
+}
+
+// TestSetupNSCancelled tests that setupNS stops retrying as soon as the context is cancelled.
+func TestSetupNSCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ecscniClient := NewClient("")
+	libcniClient := mock_libcni.NewMockCNI(ctrl)
+	ecscniClient.(*cniClient).libcni = libcniClient
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	// The first attempt fails and the task is stopped while waiting for the retry.
+	libcniClient.EXPECT().AddNetwork(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&cniTypesCurrent.Result{}, errors.New("timeout")).Do(
+		func(ctx context.Context, net *libcni.NetworkConfig, rt *libcni.RuntimeConf) {
+			cancel()
+		}).Times(1)
+	// The failed attempt is cleaned up even though the context is cancelled.
+	libcniClient.EXPECT().DelNetwork(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	config := getNetworkConfig()
+	config.SetupNSRetryConfig = &SetupNSRetryConfig{
+		BackoffMin: time.Hour,
+		BackoffMax: time.Hour,
+	}
+	start := time.Now()
+	_, err := ecscniClient.SetupNS(ctx, config, 2*time.Hour)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Minute)
+}
+
+// TestSetupNSSetupTimeout tests that setupNS stops retrying once the setup timeout is exceeded,
+// even though attempts remain.
+func TestSetupNSSetupTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ecscniClient := NewClient("")
+	libcniClient := mock_libcni.NewMockCNI(ctrl)
+	ecscniClient.(*cniClient).libcni = libcniClient
+
+	libcniClient.EXPECT().AddNetwork(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&cniTypesCurrent.Result{}, errors.New("timeout")).Times(1)
+	libcniClient.EXPECT().DelNetwork(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	config := getNetworkConfig()
+	config.SetupNSRetryConfig = &SetupNSRetryConfig{
+		BackoffMin:   time.Hour,
+		BackoffMax:   time.Hour,
+		SetupTimeout: 10 * time.Millisecond,
+	}
+	start := time.Now()
+	_, err := ecscniClient.SetupNS(context.TODO(), config, 2*time.Hour)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "namespace setup timed out")
+	assert.Less(t, time.Since(start), time.Minute)
+}
+
+// TestSetupNSCleanupBetweenAttempts tests that the networks created by a failed attempt are
+// deleted, in the reverse order, before retrying, and that cleanup errors are ignored.
+func TestSetupNSCleanupBetweenAttempts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ecscniClient := NewClient("")
+	libcniClient := mock_libcni.NewMockCNI(ctrl)
+	ecscniClient.(*cniClient).libcni = libcniClient
+
+	config := getNetworkConfig()
+	config.SetupNSRetryConfig = &SetupNSRetryConfig{
+		BackoffMin: time.Millisecond,
+		BackoffMax: time.Millisecond,
+	}
+	taskENIConfig := config.NetworkConfigs[0].CNINetworkConfig
+	ecsBridgeConfig := config.NetworkConfigs[1].CNINetworkConfig
+
+	gomock.InOrder(
+		// The task ENI endpoint is created, but the ecs-bridge endpoint setup fails.
+		libcniClient.EXPECT().AddNetwork(gomock.Any(), taskENIConfig, gomock.Any()).
+			Return(&cniTypesCurrent.Result{}, nil),
+		libcniClient.EXPECT().AddNetwork(gomock.Any(), ecsBridgeConfig, gomock.Any()).
+			Return(&cniTypesCurrent.Result{}, errors.New("timeout")),
+		// Both are deleted before retrying, even though the ecs-bridge endpoint is already gone.
+		libcniClient.EXPECT().DelNetwork(gomock.Any(), ecsBridgeConfig, gomock.Any()).
+			Return(errors.New("endpoint not found")),
+		libcniClient.EXPECT().DelNetwork(gomock.Any(), taskENIConfig, gomock.Any()).Return(nil),
+		// The retry succeeds.
+		libcniClient.EXPECT().AddNetwork(gomock.Any(), taskENIConfig, gomock.Any()).
+			Return(&cniTypesCurrent.Result{}, nil),
+		libcniClient.EXPECT().AddNetwork(gomock.Any(), ecsBridgeConfig, gomock.Any()).
+			Return(&cniTypesCurrent.Result{}, nil),
+	)
+
+	_, err := ecscniClient.SetupNS(context.TODO(), config, time.Second)
+	assert.NoError(t, err)
+}
+
+// TestSetupNSENIVerification tests that the setup succeeds when the task ENI adapter has the
+// configured address once verified.
+func TestSetupNSENIVerification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	defer func(getter func(string) ([]net.IP, error), interval time.Duration) {
+		getAdapterAddresses = getter
+		eniVerificationInterval = interval
+	}(getAdapterAddresses, eniVerificationInterval)
+	eniVerificationInterval = time.Millisecond
+	queries := 0
+	getAdapterAddresses = func(macAddress string) ([]net.IP, error) {
+		queries++
+		assert.Equal(t, eniMACAddress, macAddress)
+		if queries == 1 {
+			// The address shows up after a while.
+			return nil, nil
+		}
+		return []net.IP{net.ParseIP(ipv4Address)}, nil
+	}
+
+	ecscniClient := NewClient("")
+	libcniClient := mock_libcni.NewMockCNI(ctrl)
+	ecscniClient.(*cniClient).libcni = libcniClient
+	libcniClient.EXPECT().AddNetwork(gomock.Any(), gomock.Any(), gomock.Any()).Return(&cniTypesCurrent.Result{}, nil).Times(2)
+
+	config := getNetworkConfig()
+	config.VerifyENISetup = true
+	_, err := ecscniClient.SetupNS(context.TODO(), config, time.Second)
+	assert.NoError(t, err)
+	// The ecs-bridge endpoint, which doesn't set up an ENI, isn't verified.
+	assert.Equal(t, 2, queries)
+}
+
+// TestSetupNSENIVerificationWrongIP tests that the setup fails, and the networks are cleaned
+// up, when the task ENI adapter doesn't come up with the configured address.
+func TestSetupNSENIVerificationWrongIP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	defer func(getter func(string) ([]net.IP, error), interval time.Duration) {
+		getAdapterAddresses = getter
+		eniVerificationInterval = interval
+	}(getAdapterAddresses, eniVerificationInterval)
+	eniVerificationInterval = time.Millisecond
+	queries := 0
+	getAdapterAddresses = func(macAddress string) ([]net.IP, error) {
+		queries++
+		return []net.IP{net.ParseIP("172.31.21.41")}, nil
+	}
+
+	ecscniClient := NewClient("")
+	libcniClient := mock_libcni.NewMockCNI(ctrl)
+	ecscniClient.(*cniClient).libcni = libcniClient
+
+	config := getNetworkConfig()
+	config.VerifyENISetup = true
+	config.SetupNSRetryConfig = &SetupNSRetryConfig{MaxRetryCount: 1}
+	taskENIConfig := config.NetworkConfigs[0].CNINetworkConfig
+	gomock.InOrder(
+		libcniClient.EXPECT().AddNetwork(gomock.Any(), taskENIConfig, gomock.Any()).
+			Return(&cniTypesCurrent.Result{}, nil),
+		libcniClient.EXPECT().DelNetwork(gomock.Any(), taskENIConfig, gomock.Any()).Return(nil),
+	)
+
+	_, err := ecscniClient.SetupNS(context.TODO(), config, time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "eni verification failed")
+	assert.Equal(t, eniVerificationMaxAttempts, queries)
+}
+
+// TestGetSetupNSRetryConfig tests that unset retry parameters fall back to the defaults.
+func TestGetSetupNSRetryConfig(t *testing.T) {
+	defaults := getSetupNSRetryConfig(nil)
+	assert.Equal(t, SetupNSRetryConfig{
+		BackoffMin:      setupNSBackoffMin,
+		BackoffMax:      setupNSBackoffMax,
+		BackoffJitter:   setupNSBackoffJitter,
+		BackoffMultiple: setupNSBackoffMultiple,
+		MaxRetryCount:   setupNSMaxRetryCount,
+	}, defaults)
+
+	overridden := getSetupNSRetryConfig(&SetupNSRetryConfig{
+		BackoffMax:      2 * time.Minute,
+		MaxRetryCount:   10,
+		SetupTimeout:    5 * time.Minute,
+		InitialDelayMax: time.Second,
+	})
+	expected := defaults
+	expected.BackoffMax = 2 * time.Minute
+	expected.MaxRetryCount = 10
+	expected.SetupTimeout = 5 * time.Minute
+	expected.InitialDelayMax = time.Second
+	assert.Equal(t, expected, overridden)
+}
+
 // TestCleanupNS tests the cleanup of the task namespace when CleanupNS is called.
 func TestCleanupNS(t *testing.T) {
 	ctrl := gomock.NewController(t)
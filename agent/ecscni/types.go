@@ -14,6 +14,8 @@
 package ecscni
 
 import (
+	"time"
+
 	"github.com/containernetworking/cni/libcni"
 	"github.com/containernetworking/cni/pkg/types"
 	cniTypes "github.com/containernetworking/cni/pkg/types"
@@ -66,6 +68,43 @@ type Config struct {
 	// InstanceENIDNSServerList stores the list of dns servers for the primary instance ENI.
 	// Currently, this field is only populated for Windows and is used during task networking setup.
 	InstanceENIDNSServerList []string
+	// TaskDNSOptions are the resolver options set by the task's containers. They are passed to
+	// the vpc-eni plugin along with the DNS configuration of the task ENI. Currently, this field
+	// is only populated for Windows and is used during task networking setup.
+	TaskDNSOptions []string
+	// SetupNSRetryConfig overrides the retry behavior used while setting up the task
+	// namespace. Defaults are used when it is nil. Currently, this field is only
+	// honored on Windows, where the namespace setup is retried.
+	SetupNSRetryConfig *SetupNSRetryConfig
+	// VerifyENISetup enables the verification that the adapter of the task ENI has been
+	// assigned the configured IP addresses once the vpc-eni plugin has set it up. The setup
+	// fails if it hasn't. Currently, this field is only honored on Windows.
+	VerifyENISetup bool
+}
+
+// SetupNSRetryConfig contains the parameters of the exponential backoff used to
+// retry the task namespace setup. Fields left as zero fall back to the defaults.
+type SetupNSRetryConfig struct {
+	// BackoffMin is the initial delay between attempts.
+	BackoffMin time.Duration
+	// BackoffMax is the maximum delay between attempts.
+	BackoffMax time.Duration
+	// BackoffJitter is the jitter multiple applied to each delay.
+	BackoffJitter float64
+	// BackoffMultiple is the factor by which the delay grows after each attempt.
+	BackoffMultiple float64
+	// MaxRetryCount is the maximum number of attempts made to set up the namespace.
+	MaxRetryCount int
+	// InitialDelayMax bounds a randomized delay applied before the first attempt, which
+	// spreads the plugin invocations of tasks starting at the same time on dense hosts.
+	// The delay is picked uniformly between zero and InitialDelayMax, independently of
+	// BackoffJitter. Zero means the first attempt is made immediately.
+	InitialDelayMax time.Duration
+	// SetupTimeout is a hard ceiling on the total time spent setting up the namespace,
+	// including all attempts and the delays between them. The setup is abandoned with
+	// a timeout error once it is exceeded, regardless of the attempts remaining. Zero
+	// means the setup is bounded by MaxRetryCount only.
+	SetupTimeout time.Duration
 }
 
 // NetworkConfig wraps CNI library's NetworkConfig object. It tracks the interface device
@@ -96,8 +135,32 @@ type VPCENIPluginConfig struct {
 	ENIIPAddresses []string `json:"eniIPAddresses"`
 	// GatewayIPAddresses specifies the IPv4 address of the subnet gateway for the eni.
 	GatewayIPAddresses []string `json:"gatewayIPAddresses"`
+	// ENIIPV6Address is the ipv6 address of the eni, if one is assigned.
+	ENIIPV6Address string `json:"eniIPV6Address,omitempty"`
+	// GatewayIPV6Address specifies the IPv6 address of the subnet gateway for the eni, if the eni
+	// has an ipv6 address assigned.
+	GatewayIPV6Address string `json:"gatewayIPV6Address,omitempty"`
 	// UseExistingNetwork specifies if existing network should be used instead of creating a new one.
 	UseExistingNetwork bool `json:"useExistingNetwork"`
 	// BlockIMDS specifies if the IMDS should be blocked for the created endpoint.
 	BlockIMDS bool `json:"blockInstanceMetadata"`
 }
+
+// WithDNS sets the nameservers, search domains and resolver options passed to the plugin.
+// The given lists are copied so that the configuration doesn't alias the caller's slices.
+func (conf *VPCENIPluginConfig) WithDNS(nameservers, search, options []string) *VPCENIPluginConfig {
+	conf.DNS = types.DNS{
+		Nameservers: copyStrings(nameservers),
+		Search:      copyStrings(search),
+		Options:     copyStrings(options),
+	}
+	return conf
+}
+
+// copyStrings returns a copy of the given list, or nil if the list is empty.
+func copyStrings(list []string) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	return append([]string(nil), list...)
+}
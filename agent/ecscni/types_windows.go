@@ -43,4 +43,10 @@ var (
 	setupNSBackoffJitter   = 0.2
 	setupNSBackoffMultiple = 2.0
 	setupNSMaxRetryCount   = 5
+	// setupNSCleanupTimeout bounds the cleanup of the networks created by a failed attempt
+	// to set up the task namespace.
+	setupNSCleanupTimeout = 30 * time.Second
+	// Values for verifying the addresses of the task ENI adapter once it has been set up.
+	eniVerificationMaxAttempts = 5
+	eniVerificationInterval    = 500 * time.Millisecond
 )
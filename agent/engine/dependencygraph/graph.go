@@ -76,6 +76,8 @@ type DependencyError interface {
 type dependencyError struct {
 	err        error
 	isTerminal bool
+	// unmetDependency is the ordering dependency that caused a terminal error, if any
+	unmetDependency *apicontainer.UnmetDependency
 }
 
 func (de *dependencyError) Error() string {
@@ -86,6 +88,28 @@ func (de *dependencyError) IsTerminal() bool {
 	return de.isTerminal
 }
 
+// newUnmetDependencyError returns a terminal dependency error for an ordering dependency that
+// can never be satisfied
+func newUnmetDependencyError(err error, dependency apicontainer.DependsOn, reason string) *dependencyError {
+	return &dependencyError{
+		err:        err,
+		isTerminal: true,
+		unmetDependency: &apicontainer.UnmetDependency{
+			DependsOn: dependency,
+			Reason:    reason,
+		},
+	}
+}
+
+// UnmetDependency returns the ordering dependency that caused the dependency error, or nil if
+// the error wasn't caused by an ordering dependency that can never be satisfied
+func UnmetDependency(err DependencyError) *apicontainer.UnmetDependency {
+	if de, ok := err.(*dependencyError); ok {
+		return de.unmetDependency
+	}
+	return nil
+}
+
 // ValidDependencies takes a task and verifies that it is possible to allow all
 // containers within it to reach the desired status by proceeding in some
 // order.
@@ -281,7 +305,8 @@ func verifyContainerOrderingStatusResolvable(target *apicontainer.Container, exi
 	for _, dependency := range targetDependencies {
 		dependencyContainer, ok := existingContainers[dependency.ContainerName]
 		if !ok {
-			return nil, &dependencyError{err: fmt.Errorf("dependency graph: container ordering dependency [%v] for target [%v] does not exist.", dependencyContainer, target), isTerminal: true}
+			return nil, newUnmetDependencyError(fmt.Errorf("dependency graph: container ordering dependency [%v] for target [%v] does not exist.", dependencyContainer, target),
+				dependency, "dependency container does not exist")
 		}
 
 		// We want to check whether the dependency container has timed out only if target has not been created yet.
@@ -289,7 +314,8 @@ func verifyContainerOrderingStatusResolvable(target *apicontainer.Container, exi
 		// However, if dependency container has already stopped, then it cannot time out.
 		if targetKnown < apicontainerstatus.ContainerCreated && dependencyContainer.GetKnownStatus() != apicontainerstatus.ContainerStopped {
 			if hasDependencyTimedOut(dependencyContainer, dependency.Condition) {
-				return nil, &dependencyError{err: fmt.Errorf("dependency graph: container ordering dependency [%v] for target [%v] has timed out.", dependencyContainer, target), isTerminal: true}
+				return nil, newUnmetDependencyError(fmt.Errorf("dependency graph: container ordering dependency [%v] for target [%v] has timed out.", dependencyContainer, target),
+					dependency, "dependency container timed out before reaching the condition")
 			}
 		}
 
@@ -297,13 +323,15 @@ func verifyContainerOrderingStatusResolvable(target *apicontainer.Container, exi
 		// can then never progress to its desired state when the dependency condition is 'SUCCESS'
 		if dependency.Condition == successCondition && dependencyContainer.GetKnownStatus() == apicontainerstatus.ContainerStopped &&
 			!hasDependencyStoppedSuccessfully(dependencyContainer) {
-			return nil, &dependencyError{err: fmt.Errorf("dependency graph: failed to resolve container ordering dependency [%v] for target [%v] as dependency did not exit successfully.", dependencyContainer, target), isTerminal: true}
+			return nil, newUnmetDependencyError(fmt.Errorf("dependency graph: failed to resolve container ordering dependency [%v] for target [%v] as dependency did not exit successfully.", dependencyContainer, target),
+				dependency, "dependency container did not exit successfully")
 		}
 
 		// For any of the dependency conditions - START/COMPLETE/SUCCESS/HEALTHY, if the dependency container has
 		// not started and will not start in the future, this dependency can never be resolved.
 		if dependencyContainer.HasNotAndWillNotStart() {
-			return nil, &dependencyError{err: fmt.Errorf("dependency graph: failed to resolve container ordering dependency [%v] for target [%v] because dependency will never start", dependencyContainer, target), isTerminal: true}
+			return nil, newUnmetDependencyError(fmt.Errorf("dependency graph: failed to resolve container ordering dependency [%v] for target [%v] because dependency will never start", dependencyContainer, target),
+				dependency, "dependency container will never start")
 		}
 
 		if !resolves(target, dependencyContainer, dependency.Condition, cfg) {
@@ -1652,4 +1652,9 @@ func TestVerifyContainerOrderingStatusResolvableFailOnDependencyWontStart(t *tes
 	}
 	_, err := verifyContainerOrderingStatusResolvable(target, contMap, &config.Config{}, dummyResolves)
 	assert.Error(t, err)
+	assert.True(t, err.IsTerminal())
+	unmetDependency := UnmetDependency(err)
+	assert.NotNil(t, unmetDependency)
+	assert.Equal(t, dependencyName, unmetDependency.ContainerName)
+	assert.Equal(t, "dependency container will never start", unmetDependency.Reason)
 }
@@ -158,11 +158,18 @@ func (imageManager *dockerImageManager) RecordContainerReference(container *apic
 		return err
 	}
 	container.ImageID = imageInspected.ID
+	if imageCreatedAt, err := time.Parse(time.RFC3339Nano, imageInspected.Created); err == nil {
+		container.SetImageCreatedAt(imageCreatedAt)
+	}
 	// For older Docker versions imageDigest is not populated during transition to
 	// MANIFEST_PULLED state. Populate it here if that's the case.
 	if container.GetImageDigest() == "" {
 		imageDigest := imageManager.fetchRepoDigest(imageInspected, container)
 		container.SetImageDigest(imageDigest)
+	} else if !hasRepoDigest(imageInspected, container.GetImageDigest()) {
+		// The image that was pulled, or found in the cache, doesn't have the digest that
+		// was resolved for the container.
+		container.SetPulledImageDigest(imageManager.fetchRepoDigest(imageInspected, container))
 	}
 	added := imageManager.addContainerReferenceToExistingImageState(container)
 	if !added {
@@ -191,6 +198,20 @@ func (imageManager *dockerImageManager) fetchRepoDigest(imageInspected *types.Im
 	return resultRepoDigest
 }
 
+// hasRepoDigest checks whether the given digest is one of the repo digests of the image.
+// It returns true if the image has no repo digests, as the digest can't be verified then.
+func hasRepoDigest(imageInspected *types.ImageInspect, imageDigest string) bool {
+	if len(imageInspected.RepoDigests) == 0 {
+		return true
+	}
+	for _, repoDigest := range imageInspected.RepoDigests {
+		if strings.HasSuffix(repoDigest, "@"+imageDigest) {
+			return true
+		}
+	}
+	return false
+}
+
 func (imageManager *dockerImageManager) addContainerReferenceToExistingImageState(container *apicontainer.Container) bool {
 	// this lock is used for reading the image states in the image manager
 	imageManager.updateLock.RLock()
@@ -238,6 +238,84 @@ func TestRecordContainerReferenceWithNoImageName(t *testing.T) {
 	}
 }
 
+func TestRecordContainerReferenceImageDigestChanged(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		repoDigests            []string
+		expectedImageDigest    string
+		expectedExpectedDigest string
+	}{
+		{
+			name:                "pulled digest matches",
+			repoDigests:         []string{"testContainerImage@sha256:expected"},
+			expectedImageDigest: "sha256:expected",
+		},
+		{
+			name:                "no repo digests",
+			expectedImageDigest: "sha256:expected",
+		},
+		{
+			name:                   "pulled digest differs",
+			repoDigests:            []string{"testContainerImage@sha256:pulled"},
+			expectedImageDigest:    "sha256:pulled",
+			expectedExpectedDigest: "sha256:expected",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+			imageManager := &dockerImageManager{
+				client: client,
+				state:  dockerstate.NewTaskEngineState(),
+			}
+			imageManager.SetDataClient(data.NewNoopClient())
+
+			container := &apicontainer.Container{
+				Name:        "testContainer",
+				Image:       "testContainerImage",
+				ImageDigest: "sha256:expected",
+			}
+			imageInspected := &types.ImageInspect{
+				ID:          "sha256:qwerty",
+				RepoDigests: tc.repoDigests,
+			}
+			client.EXPECT().InspectImage(container.Image).Return(imageInspected, nil)
+
+			require.NoError(t, imageManager.RecordContainerReference(container))
+			assert.Equal(t, tc.expectedImageDigest, container.GetImageDigest())
+			assert.Equal(t, tc.expectedExpectedDigest, container.GetExpectedImageDigest())
+		})
+	}
+}
+
+func TestRecordContainerReferenceImageCreatedAt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	imageManager := &dockerImageManager{
+		client: client,
+		state:  dockerstate.NewTaskEngineState(),
+	}
+	imageManager.SetDataClient(data.NewNoopClient())
+
+	container := &apicontainer.Container{
+		Name:  "testContainer",
+		Image: "testContainerImage",
+	}
+	imageInspected := &types.ImageInspect{
+		ID:      "sha256:qwerty",
+		Created: "2024-01-02T03:04:05.123456789Z",
+	}
+	client.EXPECT().InspectImage(container.Image).Return(imageInspected, nil)
+
+	require.NoError(t, imageManager.RecordContainerReference(container))
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC), container.GetImageCreatedAt())
+}
+
 func TestAddInvalidContainerReferenceToImageState(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -242,7 +242,7 @@ func NewDockerTaskEngine(cfg *config.Config,
 		containerChangeEventStream: containerChangeEventStream,
 		imageManager:               imageManager,
 		hostResourceManager:        hostResourceManager,
-		cniClient:                  ecscni.NewClient(cfg.CNIPluginsPath),
+		cniClient:                  ecscni.NewClientWithPluginPathResolver(cfg.CNIPluginsPath, ecscni.NewPluginPathResolver(cfg.CNIPluginsPath, cfg.CNIPluginPaths)),
 		appnetClient:               appnet.CreateClient(),
 
 		metadataManager:                   metadataManager,
@@ -697,6 +697,7 @@ func updateContainerMetadata(metadata *dockerapi.DockerContainerMetadata, contai
 	container.SetCreatedAt(metadata.CreatedAt)
 	container.SetStartedAt(metadata.StartedAt)
 	container.SetFinishedAt(metadata.FinishedAt)
+	container.SetInitPID(metadata.PID)
 
 	// Set the labels if it's not set
 	if len(metadata.Labels) != 0 && len(container.GetLabels()) == 0 {
@@ -993,6 +994,7 @@ func (engine *DockerTaskEngine) EmitTaskEvent(task *apitask.Task, reason string)
 		}
 		return
 	}
+	event.RuntimePlatformVersion = engine.cfg.RuntimePlatformVersion
 	logger.Info("Preparing to send change event", logger.Fields{
 		field.TaskID: task.GetID(),
 		field.Status: event.Status.String(),
@@ -2513,6 +2515,14 @@ func (engine *DockerTaskEngine) buildCNIConfigFromTaskContainerAwsvpc(
 		BlockInstanceMetadata:    engine.cfg.AWSVPCBlockInstanceMetdata.Enabled(),
 		MinSupportedCNIVersion:   config.DefaultMinSupportedCNIVersion,
 		InstanceENIDNSServerList: engine.cfg.InstanceENIDNSServerList,
+		SetupNSRetryConfig: &ecscni.SetupNSRetryConfig{
+			BackoffMin:      engine.cfg.TaskNetworkSetupBackoffMin,
+			BackoffMax:      engine.cfg.TaskNetworkSetupBackoffMax,
+			MaxRetryCount:   engine.cfg.TaskNetworkSetupMaxRetryCount,
+			SetupTimeout:    engine.cfg.TaskNetworkSetupTimeout,
+			InitialDelayMax: engine.cfg.TaskNetworkSetupInitialDelayMax,
+		},
+		VerifyENISetup: engine.cfg.VerifyTaskENISetup.Enabled(),
 	}
 	if engine.cfg.OverrideAWSVPCLocalIPv4Address != nil &&
 		len(engine.cfg.OverrideAWSVPCLocalIPv4Address.IP) != 0 &&
@@ -2624,6 +2634,7 @@ func (engine *DockerTaskEngine) stopContainer(task *apitask.Task, container *api
 		apiTimeoutStopContainer = engine.cfg.DockerStopTimeout
 	}
 
+	container.SetStopRequested(true)
 	return engine.stopDockerContainer(dockerID, container.Name, apiTimeoutStopContainer)
 }
 
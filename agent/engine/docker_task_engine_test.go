@@ -1227,6 +1227,7 @@ func TestStopPauseContainerCleanupCalledAwsvpc(t *testing.T) {
 
 	taskEngine.(*DockerTaskEngine).stopContainer(testTask, pauseContainer)
 	require.True(t, pauseContainer.IsContainerTornDown())
+	assert.True(t, pauseContainer.IsStopRequested())
 }
 
 // TestStopPauseContainerCleanupDelayAwsvpc tests when stopping the pause container
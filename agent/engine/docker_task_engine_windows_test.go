@@ -27,6 +27,7 @@ import (
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	mock_asm_factory "github.com/aws/amazon-ecs-agent/agent/asm/factory/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
@@ -244,10 +245,16 @@ func TestCredentialSpecResourceTaskFileErr(t *testing.T) {
 }
 
 func TestBuildCNIConfigFromTaskContainer(t *testing.T) {
-	config := defaultConfig
+	cfg := defaultConfig
+	cfg.TaskNetworkSetupBackoffMin = 2 * time.Second
+	cfg.TaskNetworkSetupBackoffMax = 30 * time.Second
+	cfg.TaskNetworkSetupMaxRetryCount = 3
+	cfg.TaskNetworkSetupTimeout = 5 * time.Minute
+	cfg.TaskNetworkSetupInitialDelayMax = 10 * time.Second
+	cfg.VerifyTaskENISetup = config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled}
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
-	ctrl, _, _, taskEngine, _, _, _, _ := mocks(t, ctx, &config)
+	ctrl, _, _, taskEngine, _, _, _, _ := mocks(t, ctx, &cfg)
 	defer ctrl.Finish()
 
 	testTask := testdata.LoadTask("sleep5")
@@ -284,6 +291,14 @@ func TestBuildCNIConfigFromTaskContainer(t *testing.T) {
 	// Config for task ns setup.
 	// Config for ecs-bridge setup for the task.
 	require.Len(t, cniConfig.NetworkConfigs, 2)
+	assert.Equal(t, &ecscni.SetupNSRetryConfig{
+		BackoffMin:      2 * time.Second,
+		BackoffMax:      30 * time.Second,
+		MaxRetryCount:   3,
+		SetupTimeout:    5 * time.Minute,
+		InitialDelayMax: 10 * time.Second,
+	}, cniConfig.SetupNSRetryConfig)
+	assert.True(t, cniConfig.VerifyENISetup)
 }
 
 // TestTaskWithSteadyStateResourcesProvisioned tests container and task transitions
@@ -290,9 +290,15 @@ func (mtask *managedTask) shouldExit() bool {
 // event for the task
 func (mtask *managedTask) emitCurrentStatus() {
 	for _, container := range mtask.Containers {
-		mtask.emitContainerEvent(mtask.Task, container, "")
+		if event, ok := mtask.newContainerEvent(mtask.Task, container, ""); ok {
+			event.Reconciled = true
+			mtask.doEmitContainerEvent(event)
+		}
+	}
+	if event, ok := mtask.newTaskEvent(mtask.Task, ""); ok {
+		event.Reconciled = true
+		mtask.doEmitTaskEvent(event)
 	}
-	mtask.emitTaskEvent(mtask.Task, "")
 }
 
 // waitForHostResources waits for host resources to become available to start
@@ -491,6 +497,11 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 		shouldRestart, reason := container.RestartTracker.ShouldRestart(exitCode, container.GetStartedAt(),
 			container.GetDesiredStatus())
 		if shouldRestart {
+			restartReason := ""
+			if event.DockerContainerMetadata.Error != nil {
+				restartReason = event.DockerContainerMetadata.Error.Error()
+			}
+			container.RestartTracker.RecordRestartReason(exitCode, restartReason)
 			container.RestartTracker.RecordRestart()
 			resp := mtask.engine.startContainer(mtask.Task, container)
 			if resp.Error == nil {
@@ -647,6 +658,14 @@ func getContainerEventLogFields(c api.ContainerStateChange) logger.Fields {
 }
 
 func (mtask *managedTask) emitTaskEvent(task *apitask.Task, reason string) {
+	if event, ok := mtask.newTaskEvent(task, reason); ok {
+		mtask.doEmitTaskEvent(event)
+	}
+}
+
+// newTaskEvent creates the task state change event to be emitted for the task's
+// current known status. It returns false if no event should be emitted.
+func (mtask *managedTask) newTaskEvent(task *apitask.Task, reason string) (api.TaskStateChange, bool) {
 	taskKnownStatus := task.GetKnownStatus()
 	// Always do (idempotent) release host resources whenever state change with
 	// known status == STOPPED is done to ensure sync between tasks and host resource manager
@@ -664,7 +683,7 @@ func (mtask *managedTask) emitTaskEvent(task *apitask.Task, reason string) {
 			field.Error:       "status not recognized by ECS",
 			field.KnownStatus: taskKnownStatus.String(),
 		})
-		return
+		return api.TaskStateChange{}, false
 	}
 	event, err := api.NewTaskStateChangeEvent(task, reason)
 	if err != nil {
@@ -677,8 +696,14 @@ func (mtask *managedTask) emitTaskEvent(task *apitask.Task, reason string) {
 				field.Error:  err,
 			})
 		}
-		return
+		return event, false
 	}
+	event.RuntimePlatformVersion = mtask.cfg.RuntimePlatformVersion
+	return event, true
+}
+
+func (mtask *managedTask) doEmitTaskEvent(event api.TaskStateChange) {
+	task := event.Task
 	logger.Debug("Sending task change event", logger.Fields{
 		field.TaskID:     mtask.GetID(),
 		field.Status:     event.Status.String(),
@@ -736,6 +761,15 @@ func (mtask *managedTask) emitManagedAgentEvent(task *apitask.Task, cont *apicon
 // emitContainerEvent passes a given event up through the containerEvents channel if necessary.
 // It will omit events the backend would not process and will perform best-effort deduplication of events.
 func (mtask *managedTask) emitContainerEvent(task *apitask.Task, cont *apicontainer.Container, reason string) {
+	if event, ok := mtask.newContainerEvent(task, cont, reason); ok {
+		mtask.doEmitContainerEvent(event)
+	}
+}
+
+// newContainerEvent creates the container state change event to be emitted for the
+// container's current known status. It returns false if no event should be emitted.
+func (mtask *managedTask) newContainerEvent(task *apitask.Task, cont *apicontainer.Container,
+	reason string) (api.ContainerStateChange, bool) {
 	event, err := api.NewContainerStateChangeEvent(task, cont, reason)
 	if err != nil {
 		if _, ok := err.(api.ErrShouldNotSendEvent); ok {
@@ -750,9 +784,9 @@ func (mtask *managedTask) emitContainerEvent(task *apitask.Task, cont *apicontai
 				field.Error:     err,
 			})
 		}
-		return
+		return event, false
 	}
-	mtask.doEmitContainerEvent(event)
+	return event, true
 }
 
 func (mtask *managedTask) doEmitContainerEvent(event api.ContainerStateChange) {
@@ -1212,6 +1246,9 @@ func (mtask *managedTask) handleTerminalDependencyError(container *apicontainer.
 		field.Error:     error.Error(),
 	})
 	container.SetDesiredStatus(apicontainerstatus.ContainerStopped)
+	if unmetDependency := dependencygraph.UnmetDependency(error); unmetDependency != nil {
+		container.SetUnmetDependency(unmetDependency)
+	}
 	exitCode := 143
 	container.SetKnownExitCode(&exitCode)
 	// Change container status to STOPPED with exit code 143. This exit code is what docker reports when
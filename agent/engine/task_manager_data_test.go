@@ -22,6 +22,7 @@ import (
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
@@ -197,6 +198,7 @@ func TestHandleContainerChangeWithTaskStateChangeSaveData(t *testing.T) {
 	dataClient := newTestDataClient(t)
 
 	mTask := managedTask{
+		cfg: &config.Config{},
 		Task: &apitask.Task{
 			Arn:                 testTaskARN,
 			DesiredStatusUnsafe: apitaskstatus.TaskRunning,
@@ -904,6 +904,7 @@ func TestStartContainerTransitionsInvokesHandleContainerChange(t *testing.T) {
 	stateChangeEvents := make(chan statechange.Event)
 
 	task := &managedTask{
+		cfg: &config.Config{},
 		Task: &apitask.Task{
 			Containers: []*apicontainer.Container{
 				firstContainer,
@@ -1037,6 +1038,7 @@ func TestOnContainersUnableToTransitionStateForDesiredStoppedTask(t *testing.T)
 	stateChangeEvents := make(chan statechange.Event)
 	hostResourceManager := NewHostResourceManager(getTestHostResources())
 	task := &managedTask{
+		cfg: &config.Config{},
 		Task: &apitask.Task{
 			Containers:          []*apicontainer.Container{},
 			DesiredStatusUnsafe: apitaskstatus.TaskStopped,
@@ -1063,6 +1065,40 @@ func TestOnContainersUnableToTransitionStateForDesiredStoppedTask(t *testing.T)
 	assert.Equal(t, task.GetDesiredStatus(), apitaskstatus.TaskStopped)
 }
 
+func TestEmitCurrentStatusMarksEventsReconciled(t *testing.T) {
+	stateChangeEvents := make(chan statechange.Event)
+	hostResourceManager := NewHostResourceManager(getTestHostResources())
+	steadyStateStatus := apicontainerstatus.ContainerRunning
+	task := &managedTask{
+		cfg: &config.Config{},
+		Task: &apitask.Task{
+			Arn: "arn",
+			Containers: []*apicontainer.Container{
+				{
+					Name:                    "container",
+					KnownStatusUnsafe:       apicontainerstatus.ContainerRunning,
+					SteadyStateStatusUnsafe: &steadyStateStatus,
+				},
+			},
+			KnownStatusUnsafe:   apitaskstatus.TaskRunning,
+			DesiredStatusUnsafe: apitaskstatus.TaskRunning,
+		},
+		engine: &DockerTaskEngine{
+			stateChangeEvents:   stateChangeEvents,
+			hostResourceManager: &hostResourceManager,
+		},
+		stateChangeEvents: stateChangeEvents,
+		ctx:               context.TODO(),
+	}
+
+	go task.emitCurrentStatus()
+
+	containerEvent := <-stateChangeEvents
+	assert.True(t, containerEvent.(api.ContainerStateChange).Reconciled)
+	taskEvent := <-stateChangeEvents
+	assert.True(t, taskEvent.(api.TaskStateChange).Reconciled)
+}
+
 func TestOnContainersUnableToTransitionStateForDesiredRunningTask(t *testing.T) {
 	for _, tc := range []struct {
 		knownStatus                    apicontainerstatus.ContainerStatus
@@ -1882,6 +1918,7 @@ func TestHandleContainerChangeStopped(t *testing.T) {
 
 	hostResourceManager := NewHostResourceManager(getTestHostResources())
 	mTask := &managedTask{
+		cfg:                        &config.Config{},
 		Task:                       testdata.LoadTask("sleep5"),
 		containerChangeEventStream: containerChangeEventStream,
 		stateChangeEvents:          make(chan statechange.Event),
@@ -1976,6 +2013,7 @@ func TestHandleContainerChangeStopped_WithRestartPolicy_RestartFails(t *testing.
 	cfg := getTestConfig()
 	hostResourceManager := NewHostResourceManager(getTestHostResources())
 	mTask := &managedTask{
+		cfg:                        &cfg,
 		Task:                       testdata.LoadTask("sleep5RestartPolicy"),
 		containerChangeEventStream: containerChangeEventStream,
 		stateChangeEvents:          make(chan statechange.Event),
@@ -2028,6 +2066,7 @@ func TestHandleContainerChangeStopped_WithRestartPolicy_DesiredStopped(t *testin
 	cfg := getTestConfig()
 	hostResourceManager := NewHostResourceManager(getTestHostResources())
 	mTask := &managedTask{
+		cfg:                        &cfg,
 		Task:                       testdata.LoadTask("sleep5RestartPolicy"),
 		containerChangeEventStream: containerChangeEventStream,
 		stateChangeEvents:          make(chan statechange.Event),
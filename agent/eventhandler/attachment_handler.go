@@ -18,10 +18,12 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/api"
 	"github.com/aws/amazon-ecs-agent/agent/data"
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment/resource"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
@@ -45,6 +47,9 @@ type AttachmentEventHandler struct {
 	// lock is used to safely access the attachmentARNToHandler map
 	lock sync.Mutex
 
+	// metricsEmitter receives metrics about the submitted changes, if set
+	metricsEmitter statechange.MetricsEmitter
+
 	client ecs.ECSClient
 	ctx    context.Context
 }
@@ -63,6 +68,13 @@ type attachmentHandler struct {
 	// lock is used to ensure that the attached status of an attachment won't be sent multiple times
 	lock sync.Mutex
 
+	// submittedStatus is the last attachment status submitted to the backend. It is protected
+	// by lock.
+	submittedStatus attachment.AttachmentStatus
+
+	// metricsEmitter receives metrics about the submitted changes, if set
+	metricsEmitter statechange.MetricsEmitter
+
 	client ecs.ECSClient
 	ctx    context.Context
 }
@@ -81,6 +93,15 @@ func NewAttachmentEventHandler(ctx context.Context,
 	}
 }
 
+// SetMetricsEmitter sets the emitter that receives metrics about the attachment state
+// changes submitted by the handler. Handlers of attachments seen before the call keep
+// the previous emitter.
+func (eventHandler *AttachmentEventHandler) SetMetricsEmitter(emitter statechange.MetricsEmitter) {
+	eventHandler.lock.Lock()
+	defer eventHandler.lock.Unlock()
+	eventHandler.metricsEmitter = emitter
+}
+
 // AddStateChangeEvent adds a state change event to AttachmentEventHandler for it to handle
 func (eventHandler *AttachmentEventHandler) AddStateChangeEvent(change statechange.Event) error {
 	if change.GetEventType() != statechange.AttachmentEvent {
@@ -99,11 +120,12 @@ func (eventHandler *AttachmentEventHandler) AddStateChangeEvent(change statechan
 	eventHandler.lock.Lock()
 	if _, ok := eventHandler.attachmentARNToHandler[attachmentARN]; !ok {
 		eventHandler.attachmentARNToHandler[attachmentARN] = &attachmentHandler{
-			attachmentARN: attachmentARN,
-			dataClient:    eventHandler.dataClient,
-			client:        eventHandler.client,
-			ctx:           eventHandler.ctx,
-			backoff:       eventHandler.backoff,
+			attachmentARN:  attachmentARN,
+			dataClient:     eventHandler.dataClient,
+			client:         eventHandler.client,
+			ctx:            eventHandler.ctx,
+			backoff:        eventHandler.backoff,
+			metricsEmitter: eventHandler.metricsEmitter,
 		}
 	}
 	eventHandler.lock.Unlock()
@@ -136,12 +158,23 @@ func (handler *attachmentHandler) submitAttachmentEventOnce(attachmentChange *ap
 		return nil
 	}
 
+	attachmentStatus := attachmentChange.Attachment.GetAttachmentStatus()
+	if !handler.submittedStatus.CanTransitionTo(attachmentStatus) {
+		seelog.Warnf("AttachmentHandler: not sending attachment state change [%s] as it regresses the submitted status %s",
+			attachmentChange.String(), handler.submittedStatus.String())
+		return nil
+	}
+
 	seelog.Infof("AttachmentHandler: sending attachment state change: %s", attachmentChange.String())
-	if err := handler.client.SubmitAttachmentStateChange(*attachmentChange.ToECSAgent()); err != nil {
+	submitStartedAt := time.Now()
+	err := handler.client.SubmitAttachmentStateChange(*attachmentChange.ToECSAgent())
+	statechange.EmitSubmission(handler.metricsEmitter, statechange.MetricsKindAttachment, submitStartedAt, err)
+	if err != nil {
 		seelog.Errorf("AttachmentHandler: error submitting attachment state change [%s]: %v", attachmentChange.String(), err)
 		return err
 	}
 	seelog.Debugf("AttachmentHandler: submitted attachment state change: %s", attachmentChange.String())
+	handler.submittedStatus = attachmentStatus
 
 	attachmentChange.Attachment.SetSentStatus()
 	attachmentChange.Attachment.StopAckTimer()
@@ -24,6 +24,7 @@ import (
 
 	"github.com/aws/amazon-ecs-agent/agent/api"
 	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/statechange"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment/resource"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
@@ -225,6 +226,42 @@ func TestSubmitAttachmentEventSucceeds(t *testing.T) {
 	assert.Len(t, res, 1)
 }
 
+func TestSubmitAttachmentEventEmitsMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_ecs.NewMockECSClient(ctrl)
+
+	attachmentEvent := eniAttachmentEvent(attachmentARN)
+	assert.NoError(t, attachmentEvent.Attachment.StartTimer(func() {}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	eventHandler := NewAttachmentEventHandler(ctx, newTestDataClient(t), client)
+	// use smaller backoff value for unit test
+	eventHandler.backoff = retry.NewExponentialBackoff(xSubmitStateBackoffMin, xSubmitStateBackoffMax,
+		xSubmitStateBackoffJitterMultiple, xSubmitStateBackoffMultiple)
+	emitter := newFakeMetricsEmitter()
+	eventHandler.SetMetricsEmitter(emitter)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	gomock.InOrder(
+		client.EXPECT().SubmitAttachmentStateChange(gomock.Any()).Return(errors.New("error")),
+		client.EXPECT().SubmitAttachmentStateChange(gomock.Any()).Return(nil).Do(func(interface{}) {
+			wg.Done()
+		}),
+	)
+
+	require.NoError(t, eventHandler.AddStateChangeEvent(attachmentEvent))
+	wg.Wait()
+
+	// The failed attempt is timed but not counted as submitted
+	assert.Eventually(t, func() bool {
+		submitted, observed := emitter.counts(statechange.MetricsKindAttachment)
+		return submitted == 1 && observed == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
 func TestSubmitAttachmentEventAttachmentExpired(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -274,6 +311,27 @@ func TestSubmitAttachmentEventAttachmentIsSent(t *testing.T) {
 	attachmentEvent.Attachment.StopAckTimer()
 }
 
+func TestSubmitAttachmentEventStatusRegression(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_ecs.NewMockECSClient(ctrl)
+
+	attachmentEvent := eniAttachmentEvent(attachmentARN)
+	attachmentEvent.Attachment.(*ni.ENIAttachment).Status = attachment.AttachmentAttached
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := &attachmentHandler{
+		client:          client,
+		ctx:             ctx,
+		submittedStatus: attachment.AttachmentDetached,
+	}
+	defer cancel()
+
+	// no SubmitAttachmentStateChange should happen, as ATTACHED can't follow DETACHED
+	handler.submitAttachmentEvent(&attachmentEvent)
+	assert.False(t, attachmentEvent.Attachment.IsSent())
+}
+
 func eniAttachmentEvent(attachmentARN string) api.AttachmentStateChange {
 	return api.AttachmentStateChange{
 		Attachment: &ni.ENIAttachment{
@@ -65,6 +65,7 @@ type TaskHandler struct {
 	//  taskHandlerLock is used to safely access the following maps:
 	// * taskToEvents
 	// * tasksToContainerStates
+	// as well as the metricsEmitter
 	lock sync.RWMutex
 
 	// dataClient is used to save changes to database, mainly to save
@@ -78,6 +79,13 @@ type TaskHandler struct {
 	minDrainEventsFrequency time.Duration
 	maxDrainEventsFrequency time.Duration
 
+	// suppressedEvents counts the changes that were intentionally dropped
+	// instead of being submitted to ECS
+	suppressedEvents suppressedEventCounter
+
+	// metricsEmitter receives metrics about the submitted changes, if set
+	metricsEmitter statechange.MetricsEmitter
+
 	state  dockerstate.TaskEngineState
 	client ecs.ECSClient
 	ctx    context.Context
@@ -168,6 +176,30 @@ func (handler *TaskHandler) AddStateChangeEvent(change statechange.Event, client
 	}
 }
 
+// SuppressedEventCounts returns the number of state changes that were
+// intentionally dropped instead of being submitted to ECS, keyed by the reason
+// they were dropped
+func (handler *TaskHandler) SuppressedEventCounts() map[SuppressionReason]uint64 {
+	return handler.suppressedEvents.snapshot()
+}
+
+// SetMetricsEmitter sets the emitter that receives metrics about the state changes
+// submitted by the handler. Events being submitted when it's called may still be
+// reported to the previous emitter.
+func (handler *TaskHandler) SetMetricsEmitter(emitter statechange.MetricsEmitter) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+	handler.metricsEmitter = emitter
+}
+
+// getMetricsEmitter returns the emitter that receives metrics about the state changes
+// submitted by the handler
+func (handler *TaskHandler) getMetricsEmitter() statechange.MetricsEmitter {
+	handler.lock.RLock()
+	defer handler.lock.RUnlock()
+	return handler.metricsEmitter
+}
+
 // startDrainEventsTicker starts a ticker that periodically drains the events queue
 // by submitting state change events to the ECS backend
 func (handler *TaskHandler) startDrainEventsTicker() {
@@ -385,6 +417,10 @@ func (taskEvents *taskSendableEvents) sendChange(change *sendableEvent,
 // to ECS. The error is used by the backoff handler to backoff before retrying the
 // state change submission for the first event
 func (taskEvents *taskSendableEvents) submitFirstEvent(handler *TaskHandler, backoff retry.Backoff) (bool, error) {
+	// The emitter is read before acquiring the lock of the event list, as the handler
+	// lock is otherwise acquired first.
+	metricsEmitter := handler.getMetricsEmitter()
+
 	seelog.Debug("TaskHandler: Acquiring lock for sending event...")
 	taskEvents.lock.Lock()
 	defer taskEvents.lock.Unlock()
@@ -403,24 +439,33 @@ func (taskEvents *taskSendableEvents) submitFirstEvent(handler *TaskHandler, bac
 
 	if event.containerShouldBeSent() {
 		if err := event.send(sendContainerStatusToECS, setContainerChangeSent, "container",
-			handler.client, eventToSubmit, handler.dataClient, backoff, taskEvents); err != nil {
+			statechange.MetricsKindContainer, metricsEmitter, handler.client, eventToSubmit, handler.dataClient, backoff, taskEvents); err != nil {
+			if reason, removed := handleUnsubmittableEvent(err, taskEvents.events, eventToSubmit); removed {
+				handler.suppressedEvents.increment(reason)
+			}
 			return false, err
 		}
 	} else if event.taskShouldBeSent() {
+		event.setAgentResourcePressure(taskEvents.events.Len())
 		if err := event.send(sendTaskStatusToECS, setTaskChangeSent, "task",
-			handler.client, eventToSubmit, handler.dataClient, backoff, taskEvents); err != nil {
-			handleInvalidParamException(err, taskEvents.events, eventToSubmit)
+			statechange.MetricsKindTask, metricsEmitter, handler.client, eventToSubmit, handler.dataClient, backoff, taskEvents); err != nil {
+			if reason, removed := handleUnsubmittableEvent(err, taskEvents.events, eventToSubmit); removed {
+				handler.suppressedEvents.increment(reason)
+			}
 			return false, err
 		}
 	} else if event.taskAttachmentShouldBeSent() {
 		if err := event.send(sendTaskStatusToECS, setTaskAttachmentSent, "task attachment",
-			handler.client, eventToSubmit, handler.dataClient, backoff, taskEvents); err != nil {
-			handleInvalidParamException(err, taskEvents.events, eventToSubmit)
+			statechange.MetricsKindAttachment, metricsEmitter, handler.client, eventToSubmit, handler.dataClient, backoff, taskEvents); err != nil {
+			if reason, removed := handleUnsubmittableEvent(err, taskEvents.events, eventToSubmit); removed {
+				handler.suppressedEvents.increment(reason)
+			}
 			return false, err
 		}
 	} else {
 		// Shouldn't be sent as either a task or container change event; must have been already sent
 		logger.Info("TaskHandler: Not submitting redundant event; just removing", event.toFields())
+		handler.suppressedEvents.increment(event.suppressionReason())
 		taskEvents.events.Remove(eventToSubmit)
 	}
 
@@ -438,12 +483,27 @@ func (taskEvents *taskSendableEvents) toStringUnsafe() string {
 		taskEvents.taskARN, taskEvents.sending, taskEvents.createdAt.String())
 }
 
-// handleInvalidParamException removes the event from event queue when its parameters are
-// invalid to reduce redundant API call
-func handleInvalidParamException(err error, events *list.List, eventToSubmit *list.Element) {
-	if utils.IsAWSErrorCodeEqual(err, ecsmodel.ErrCodeInvalidParameterException) {
-		event := eventToSubmit.Value.(*sendableEvent)
-		logger.Warn("TaskHandler: Event is sent with invalid parameters; just removing", event.toFields())
-		events.Remove(eventToSubmit)
+// handleUnsubmittableEvent removes the event from event queue when it can never be
+// submitted, to reduce redundant API calls: when it regresses the status already sent,
+// fails the state change validation or is rejected by ECS because of invalid parameters.
+// It returns the reason the event was removed for and true if the event was removed
+func handleUnsubmittableEvent(err error, events *list.List, eventToSubmit *list.Element) (SuppressionReason, bool) {
+	var validationErr *ecs.StateChangeValidationError
+	var reason SuppressionReason
+	switch {
+	case errors.Is(err, ecs.ErrStatusRegression):
+		reason = SuppressionReasonRegressive
+	case errors.As(err, &validationErr):
+		reason = SuppressionReasonValidation
+	case utils.IsAWSErrorCodeEqual(err, ecsmodel.ErrCodeInvalidParameterException):
+		reason = SuppressionReasonRejected
+	default:
+		return "", false
 	}
+	event := eventToSubmit.Value.(*sendableEvent)
+	fields := event.toFields()
+	fields["suppressionReason"] = string(reason)
+	logger.Warn("TaskHandler: Event cannot be submitted; just removing", fields)
+	events.Remove(eventToSubmit)
+	return reason, true
 }
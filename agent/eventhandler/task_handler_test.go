@@ -106,6 +106,179 @@ func TestSendsEventsOneEventRetries(t *testing.T) {
 	wg.Wait()
 }
 
+// fakeMetricsEmitter records the metrics emitted about state change submissions
+type fakeMetricsEmitter struct {
+	submitted map[string]int
+	observed  map[string]int
+	// throttled records, for each queue delay observed, whether the submission was
+	// delayed by throttling
+	throttled map[string][]bool
+	lock      sync.Mutex
+}
+
+func newFakeMetricsEmitter() *fakeMetricsEmitter {
+	return &fakeMetricsEmitter{
+		submitted: make(map[string]int),
+		observed:  make(map[string]int),
+		throttled: make(map[string][]bool),
+	}
+}
+
+func (emitter *fakeMetricsEmitter) IncStateChangeSubmitted(kind string) {
+	emitter.lock.Lock()
+	defer emitter.lock.Unlock()
+	emitter.submitted[kind]++
+}
+
+func (emitter *fakeMetricsEmitter) ObserveSubmitLatency(kind string, d time.Duration) {
+	emitter.lock.Lock()
+	defer emitter.lock.Unlock()
+	emitter.observed[kind]++
+}
+
+func (emitter *fakeMetricsEmitter) ObserveQueueDelay(kind string, d time.Duration, delayedByThrottling bool) {
+	emitter.lock.Lock()
+	defer emitter.lock.Unlock()
+	emitter.throttled[kind] = append(emitter.throttled[kind], delayedByThrottling)
+}
+
+func (emitter *fakeMetricsEmitter) queueDelays(kind string) []bool {
+	emitter.lock.Lock()
+	defer emitter.lock.Unlock()
+	return append([]bool(nil), emitter.throttled[kind]...)
+}
+
+func (emitter *fakeMetricsEmitter) counts(kind string) (int, int) {
+	emitter.lock.Lock()
+	defer emitter.lock.Unlock()
+	return emitter.submitted[kind], emitter.observed[kind]
+}
+
+func TestSendsEventsEmitsMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_ecs.NewMockECSClient(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := NewTaskHandler(ctx, data.NewNoopClient(), dockerstate.NewTaskEngineState(), client)
+	defer cancel()
+	emitter := newFakeMetricsEmitter()
+	handler.SetMetricsEmitter(emitter)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	retriable := apierrors.NewRetriableError(apierrors.NewRetriable(true), errors.New("test"))
+	gomock.InOrder(
+		client.EXPECT().SubmitTaskStateChange(gomock.Any()).Return(retriable).Do(func(interface{}) { wg.Done() }),
+		client.EXPECT().SubmitTaskStateChange(gomock.Any()).Return(nil).Do(func(interface{}) { wg.Done() }),
+	)
+
+	handler.AddStateChangeEvent(taskEvent(taskARN), client)
+
+	wg.Wait()
+
+	// The failed attempt is timed but not counted as submitted
+	assert.Eventually(t, func() bool {
+		submitted, observed := emitter.counts(statechange.MetricsKindTask)
+		return submitted == 1 && observed == 2
+	}, time.Second, 10*time.Millisecond)
+	// Only the successful submission reports its queue delay
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]bool{false}, emitter.queueDelays(statechange.MetricsKindTask))
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSetMetricsEmitterConcurrentWithSubmission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_ecs.NewMockECSClient(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := NewTaskHandler(ctx, data.NewNoopClient(), dockerstate.NewTaskEngineState(), client)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(10)
+	client.EXPECT().SubmitTaskStateChange(gomock.Any()).Return(nil).Do(func(interface{}) { wg.Done() }).Times(10)
+
+	for i := 0; i < 10; i++ {
+		go handler.SetMetricsEmitter(newFakeMetricsEmitter())
+		handler.AddStateChangeEvent(taskEvent(taskARN+strconv.Itoa(i)), client)
+	}
+
+	wg.Wait()
+}
+
+func TestSendsEventsThrottledEventReportsDelay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_ecs.NewMockECSClient(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := NewTaskHandler(ctx, data.NewNoopClient(), dockerstate.NewTaskEngineState(), client)
+	defer cancel()
+	emitter := newFakeMetricsEmitter()
+	handler.SetMetricsEmitter(emitter)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	taskEvent := taskEvent(taskARN)
+
+	gomock.InOrder(
+		client.EXPECT().SubmitTaskStateChange(gomock.Any()).Do(func(change ecs.TaskStateChange) {
+			assert.False(t, change.SubmissionDelayedByThrottling)
+			wg.Done()
+		}).Return(awserr.New("ThrottlingException", "Rate exceeded", nil)),
+		client.EXPECT().SubmitTaskStateChange(gomock.Any()).Do(func(change ecs.TaskStateChange) {
+			assert.True(t, change.SubmissionDelayedByThrottling)
+			assert.NotZero(t, change.QueueDelay)
+			wg.Done()
+		}).Return(nil),
+	)
+
+	handler.AddStateChangeEvent(taskEvent, client)
+
+	wg.Wait()
+
+	assert.Eventually(t, func() bool {
+		delays := emitter.queueDelays(statechange.MetricsKindTask)
+		return len(delays) == 1 && delays[0]
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSendsEventsWrappedThrottledEventReportsDelay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_ecs.NewMockECSClient(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := NewTaskHandler(ctx, data.NewNoopClient(), dockerstate.NewTaskEngineState(), client)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	// The throttling error is wrapped the way the ECS client wraps submission errors
+	throttled := apierrors.NewRetriableError(apierrors.NewRetriable(true),
+		awserr.New("ThrottlingException", "Rate exceeded", nil))
+	gomock.InOrder(
+		client.EXPECT().SubmitTaskStateChange(gomock.Any()).Do(func(change ecs.TaskStateChange) {
+			assert.False(t, change.SubmissionDelayedByThrottling)
+			wg.Done()
+		}).Return(throttled),
+		client.EXPECT().SubmitTaskStateChange(gomock.Any()).Do(func(change ecs.TaskStateChange) {
+			assert.True(t, change.SubmissionDelayedByThrottling)
+			wg.Done()
+		}).Return(nil),
+	)
+
+	handler.AddStateChangeEvent(taskEvent(taskARN), client)
+
+	wg.Wait()
+}
+
 func TestSendsEventsInvalidParametersEventsRemoved(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -132,6 +305,68 @@ func TestSendsEventsInvalidParametersEventsRemoved(t *testing.T) {
 	handler.tasksToEvents[taskARN].lock.Lock()
 	assert.Equal(t, 0, handler.tasksToEvents[taskARN].events.Len())
 	handler.tasksToEvents[taskARN].lock.Unlock()
+	assert.Equal(t, uint64(1), handler.SuppressedEventCounts()[SuppressionReasonRejected])
+}
+
+func TestSendsEventsFailingValidationEventsRemoved(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_ecs.NewMockECSClient(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := NewTaskHandler(ctx, data.NewNoopClient(), dockerstate.NewTaskEngineState(), client)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	taskEvent := taskEvent(taskARN)
+
+	client.EXPECT().SubmitTaskStateChange(gomock.Any()).Do(func(interface{}) {
+		wg.Done()
+	}).Return(&ecs.StateChangeValidationError{Field: "TaskARN", Reason: "must be set"})
+
+	handler.AddStateChangeEvent(taskEvent, client)
+
+	wg.Wait()
+	// Require the lock to wait for submitFirstEvent to be finished
+	handler.tasksToEvents[taskARN].lock.Lock()
+	assert.Equal(t, 0, handler.tasksToEvents[taskARN].events.Len())
+	handler.tasksToEvents[taskARN].lock.Unlock()
+	counts := handler.SuppressedEventCounts()
+	assert.Equal(t, uint64(1), counts[SuppressionReasonValidation])
+	assert.Zero(t, counts[SuppressionReasonRejected])
+}
+
+func TestSendsEventsStatusRegressionEventsRemoved(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_ecs.NewMockECSClient(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := NewTaskHandler(ctx, data.NewNoopClient(), dockerstate.NewTaskEngineState(), client)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	task := &apitask.Task{}
+	taskEvent := api.TaskStateChange{TaskARN: taskARN, Status: apitaskstatus.TaskRunning, Task: task}
+
+	client.EXPECT().SubmitTaskStateChange(gomock.Any()).Do(func(interface{}) {
+		wg.Done()
+	}).Return(ecs.ErrStatusRegression)
+
+	handler.AddStateChangeEvent(taskEvent, client)
+
+	wg.Wait()
+	// Require the lock to wait for submitFirstEvent to be finished
+	handler.tasksToEvents[taskARN].lock.Lock()
+	assert.Equal(t, 0, handler.tasksToEvents[taskARN].events.Len())
+	handler.tasksToEvents[taskARN].lock.Unlock()
+	// The dropped change is counted as regressive rather than recorded as sent
+	assert.Equal(t, uint64(1), handler.SuppressedEventCounts()[SuppressionReasonRegressive])
+	assert.Equal(t, apitaskstatus.TaskStatusNone, task.GetSentStatus())
 }
 
 func TestSendsEventsConcurrentLimit(t *testing.T) {
@@ -510,6 +745,111 @@ func TestSubmitTaskEventsWhenSubmittingTaskRunningAfterStopped(t *testing.T) {
 	ok, err = taskEvents.submitFirstEvent(handler, backoff)
 	assert.NoError(t, err)
 	assert.True(t, ok)
+	assert.Equal(t, map[SuppressionReason]uint64{SuppressionReasonRegressive: 1}, handler.SuppressedEventCounts())
+}
+
+func TestSubmitTaskEventsInvalidContainerEventRemoved(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+	client := mock_ecs.NewMockECSClient(ctrl)
+
+	handler := &TaskHandler{
+		state:                  state,
+		submitSemaphore:        utils.NewSemaphore(concurrentEventCalls),
+		tasksToEvents:          make(map[string]*taskSendableEvents),
+		tasksToContainerStates: make(map[string][]api.ContainerStateChange),
+		client:                 client,
+		dataClient:             data.NewNoopClient(),
+	}
+
+	taskEvents := &taskSendableEvents{events: list.New(),
+		sending:   false,
+		createdAt: time.Now(),
+		taskARN:   taskARN,
+	}
+	exitCode := 0
+	taskEvents.events.PushBack(newSendableContainerEvent(api.ContainerStateChange{
+		TaskArn:       taskARN,
+		ContainerName: "containerName",
+		Status:        apicontainerstatus.ContainerRunning,
+		ExitCode:      &exitCode,
+		Container:     &apicontainer.Container{},
+	}))
+	taskEvents.events.PushBack(newSendableTaskEvent(api.TaskStateChange{
+		TaskARN: taskARN,
+		Status:  apitaskstatus.TaskRunning,
+		Task:    &apitask.Task{},
+	}))
+	handler.tasksToEvents[taskARN] = taskEvents
+
+	backoff := mock_retry.NewMockBackoff(ctrl)
+	validationErr := &ecs.StateChangeValidationError{Field: "RuntimeID", Reason: "must not be empty"}
+	gomock.InOrder(
+		client.EXPECT().SubmitContainerStateChange(gomock.Any()).Return(validationErr),
+		client.EXPECT().SubmitTaskStateChange(gomock.Any()).Do(func(change ecs.TaskStateChange) {
+			assert.Equal(t, apitaskstatus.TaskRunning, change.Status)
+		}),
+		backoff.EXPECT().Reset(),
+	)
+
+	// The invalid container change is dropped rather than retried
+	ok, err := taskEvents.submitFirstEvent(handler, backoff)
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, validationErr)
+	assert.Equal(t, 1, taskEvents.events.Len())
+
+	// The task change queued behind it is still sent
+	ok, err = taskEvents.submitFirstEvent(handler, backoff)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, map[SuppressionReason]uint64{SuppressionReasonValidation: 1}, handler.SuppressedEventCounts())
+}
+
+func TestSubmitTaskEventsContainerEventEmitsQueueDelay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+	client := mock_ecs.NewMockECSClient(ctrl)
+	emitter := newFakeMetricsEmitter()
+
+	handler := &TaskHandler{
+		state:                  state,
+		submitSemaphore:        utils.NewSemaphore(concurrentEventCalls),
+		tasksToEvents:          make(map[string]*taskSendableEvents),
+		tasksToContainerStates: make(map[string][]api.ContainerStateChange),
+		client:                 client,
+		dataClient:             data.NewNoopClient(),
+		metricsEmitter:         emitter,
+	}
+
+	taskEvents := &taskSendableEvents{events: list.New(),
+		sending:   false,
+		createdAt: time.Now(),
+		taskARN:   taskARN,
+	}
+	exitCode := 0
+	taskEvents.events.PushBack(newSendableContainerEvent(api.ContainerStateChange{
+		TaskArn:       taskARN,
+		ContainerName: "containerName",
+		Status:        apicontainerstatus.ContainerRunning,
+		ExitCode:      &exitCode,
+		Container:     &apicontainer.Container{},
+	}))
+	handler.tasksToEvents[taskARN] = taskEvents
+
+	backoff := mock_retry.NewMockBackoff(ctrl)
+	gomock.InOrder(
+		client.EXPECT().SubmitContainerStateChange(gomock.Any()).Return(nil),
+		backoff.EXPECT().Reset(),
+	)
+
+	ok, err := taskEvents.submitFirstEvent(handler, backoff)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false}, emitter.queueDelays(statechange.MetricsKindContainer))
 }
 
 func TestSubmitTaskEventsWhenSubmittingTaskStoppedAfterRunning(t *testing.T) {
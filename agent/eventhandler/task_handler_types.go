@@ -15,21 +15,72 @@ package eventhandler
 
 import (
 	"container/list"
+	"errors"
 	"sync"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/api"
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/statechange"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/cihub/seelog"
 )
 
+// SuppressionReason describes why a state change was intentionally dropped
+// by the task handler instead of being submitted to ECS
+type SuppressionReason string
+
+const (
+	// SuppressionReasonRedundant is used when the change has already been sent
+	SuppressionReasonRedundant SuppressionReason = "Redundant"
+	// SuppressionReasonRegressive is used when a later status has already been
+	// sent for the task or container
+	SuppressionReasonRegressive SuppressionReason = "Regressive"
+	// SuppressionReasonValidation is used when the change failed the validation
+	// of the state change, so it was never submitted to ECS
+	SuppressionReasonValidation SuppressionReason = "Validation"
+	// SuppressionReasonRejected is used when ECS rejected the change because of
+	// invalid parameters
+	SuppressionReasonRejected SuppressionReason = "Rejected"
+)
+
+// suppressedEventCounter counts the state changes dropped by the task handler,
+// keyed by the reason they were dropped
+type suppressedEventCounter struct {
+	counts map[SuppressionReason]uint64
+	lock   sync.RWMutex
+}
+
+func (counter *suppressedEventCounter) increment(reason SuppressionReason) {
+	counter.lock.Lock()
+	defer counter.lock.Unlock()
+
+	if counter.counts == nil {
+		counter.counts = make(map[SuppressionReason]uint64)
+	}
+	counter.counts[reason]++
+}
+
+func (counter *suppressedEventCounter) snapshot() map[SuppressionReason]uint64 {
+	counter.lock.RLock()
+	defer counter.lock.RUnlock()
+
+	counts := make(map[SuppressionReason]uint64, len(counter.counts))
+	for reason, count := range counter.counts {
+		counts[reason] = count
+	}
+	return counts
+}
+
 // a state change that may have a container and, optionally, a task event to
 // send
 type sendableEvent struct {
@@ -42,14 +93,29 @@ type sendableEvent struct {
 	taskSent   bool
 	taskChange api.TaskStateChange
 
+	// queuedAt is the time the event was queued for submission
+	queuedAt time.Time
+	// throttled indicates that a submission attempt of the event was throttled
+	throttled bool
+
 	lock sync.RWMutex
 }
 
+func newSendableContainerEvent(event api.ContainerStateChange) *sendableEvent {
+	return &sendableEvent{
+		isContainerEvent: true,
+		containerSent:    false,
+		containerChange:  event,
+		queuedAt:         time.Now(),
+	}
+}
+
 func newSendableTaskEvent(event api.TaskStateChange) *sendableEvent {
 	return &sendableEvent{
 		isContainerEvent: false,
 		taskSent:         false,
 		taskChange:       event,
+		queuedAt:         time.Now(),
 	}
 }
 
@@ -108,6 +174,17 @@ func (event *sendableEvent) taskShouldBeSent() bool {
 	return false
 }
 
+// setAgentResourcePressure attaches a snapshot of the agent's resource usage to
+// STOPPED task changes
+func (event *sendableEvent) setAgentResourcePressure(eventQueueDepth int) {
+	event.lock.Lock()
+	defer event.lock.Unlock()
+
+	if !event.isContainerEvent && event.taskChange.Status == apitaskstatus.TaskStopped {
+		event.taskChange.AgentResourcePressure = ecs.NewAgentResourcePressure(eventQueueDepth)
+	}
+}
+
 func (event *sendableEvent) taskAttachmentShouldBeSent() bool {
 	event.lock.RLock()
 	defer event.lock.RUnlock()
@@ -134,6 +211,56 @@ func (event *sendableEvent) containerShouldBeSent() bool {
 	return true
 }
 
+// suppressionReason returns the reason an event that should not be sent is
+// being dropped. Events for which a later status has already been sent are
+// regressive, all others are redundant
+func (event *sendableEvent) suppressionReason() SuppressionReason {
+	event.lock.RLock()
+	defer event.lock.RUnlock()
+
+	if event.isContainerEvent {
+		cevent := event.containerChange
+		if cevent.Container != nil && cevent.Container.GetSentStatus() > cevent.Status {
+			return SuppressionReasonRegressive
+		}
+		return SuppressionReasonRedundant
+	}
+	tevent := event.taskChange
+	if tevent.Task != nil && tevent.Task.GetSentStatus() > tevent.Status {
+		return SuppressionReasonRegressive
+	}
+	return SuppressionReasonRedundant
+}
+
+// setThrottled marks the event as having been delayed by throttling
+func (event *sendableEvent) setThrottled() {
+	event.lock.Lock()
+	defer event.lock.Unlock()
+
+	event.throttled = true
+}
+
+// emitQueueDelay reports how long the event waited in the queue and whether it
+// was delayed by throttling to 'metricsEmitter'
+func (event *sendableEvent) emitQueueDelay(metricsEmitter statechange.MetricsEmitter, metricsKind string) {
+	event.lock.RLock()
+	defer event.lock.RUnlock()
+
+	statechange.EmitQueueDelay(metricsEmitter, metricsKind, event.queuedAt, event.throttled)
+}
+
+// setSubmissionDelay records on the task state change how long the event waited
+// in the queue and whether it was delayed by throttling
+func (event *sendableEvent) setSubmissionDelay(taskStateChange *ecs.TaskStateChange) {
+	event.lock.RLock()
+	defer event.lock.RUnlock()
+
+	taskStateChange.SubmissionDelayedByThrottling = event.throttled
+	if !event.queuedAt.IsZero() {
+		taskStateChange.QueueDelay = time.Since(event.queuedAt)
+	}
+}
+
 func (event *sendableEvent) setSent() {
 	event.lock.Lock()
 	defer event.lock.Unlock()
@@ -145,11 +272,14 @@ func (event *sendableEvent) setSent() {
 }
 
 // send tries to send an event, specified by 'eventToSubmit', of type
-// 'eventType' to ECS
+// 'eventType' to ECS. The submission is reported to 'metricsEmitter' as a
+// change of kind 'metricsKind'
 func (event *sendableEvent) send(
 	sendStatusToECS sendStatusChangeToECS,
 	setChangeSent setStatusSent,
 	eventType string,
+	metricsKind string,
+	metricsEmitter statechange.MetricsEmitter,
 	client ecs.ECSClient,
 	eventToSubmit *list.Element,
 	dataClient data.Client,
@@ -159,11 +289,18 @@ func (event *sendableEvent) send(
 	fields := event.toFields()
 	logger.Info("Sending state change to ECS", fields)
 	// Try submitting the change to ECS
-	if err := sendStatusToECS(client, event); err != nil {
+	submitStartedAt := time.Now()
+	err := sendStatusToECS(client, event)
+	statechange.EmitSubmission(metricsEmitter, metricsKind, submitStartedAt, err)
+	if err != nil {
+		if isThrottleError(err) {
+			event.setThrottled()
+		}
 		fields[field.Error] = err
 		logger.Error("Unretriable error sending state change to ECS", fields)
 		return err
 	}
+	event.emitQueueDelay(metricsEmitter, metricsKind)
 	// submitted; ensure we don't retry it
 	event.setSent()
 	// Mark event as sent
@@ -174,6 +311,13 @@ func (event *sendableEvent) send(
 	return nil
 }
 
+// isThrottleError returns true if the error, or an error it wraps, is an AWS error
+// indicating that the request was throttled
+func isThrottleError(err error) bool {
+	var awsErr awserr.Error
+	return errors.As(err, &awsErr) && request.IsErrorThrottle(awsErr)
+}
+
 // sendStatusChangeToECS defines a function type for invoking the appropriate ECS state change API
 type sendStatusChangeToECS func(client ecs.ECSClient, event *sendableEvent) error
 
@@ -200,6 +344,7 @@ func sendTaskStatusToECS(client ecs.ECSClient, event *sendableEvent) error {
 	if err != nil {
 		return err
 	}
+	event.setSubmissionDelay(taskStateChange)
 	return client.SubmitTaskStateChange(*taskStateChange)
 }
 
@@ -266,6 +411,8 @@ func updataTaskSentStatus(task *apitask.Task, status apitaskstatus.TaskStatus, d
 func updateContainerSentStatus(container *apicontainer.Container, status apicontainerstatus.ContainerStatus, dataClient data.Client) {
 	if container.GetSentStatus() < status {
 		container.SetSentStatus(status)
+		// The change of the image digest has been reported with the state change.
+		container.ClearExpectedImageDigest()
 		if err := dataClient.SaveContainer(container); err != nil {
 			seelog.Errorf("Failed to update container sent status in database for container %s: %v", container.Name, err)
 		}
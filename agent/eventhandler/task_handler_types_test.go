@@ -17,6 +17,7 @@
 package eventhandler
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -27,9 +28,11 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/data"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -40,14 +43,6 @@ const (
 	testAttachmentARN = "arn:aws:ecs:us-west-2:1234567890:attachment/abc"
 )
 
-func newSendableContainerEvent(event api.ContainerStateChange) *sendableEvent {
-	return &sendableEvent{
-		isContainerEvent: true,
-		containerSent:    false,
-		containerChange:  event,
-	}
-}
-
 func TestShouldContainerEventBeSent(t *testing.T) {
 	event := newSendableContainerEvent(api.ContainerStateChange{
 		Status: apicontainerstatus.ContainerStopped,
@@ -276,6 +271,36 @@ func TestShouldTaskEventBeSent(t *testing.T) {
 	}
 }
 
+func TestSetAgentResourcePressure(t *testing.T) {
+	stoppedEvent := newSendableTaskEvent(api.TaskStateChange{
+		Status: apitaskstatus.TaskStopped,
+	})
+	stoppedEvent.setAgentResourcePressure(2)
+	require.NotNil(t, stoppedEvent.taskChange.AgentResourcePressure)
+	assert.Equal(t, 2, stoppedEvent.taskChange.AgentResourcePressure.EventQueueDepth)
+
+	runningEvent := newSendableTaskEvent(api.TaskStateChange{
+		Status: apitaskstatus.TaskRunning,
+	})
+	runningEvent.setAgentResourcePressure(2)
+	assert.Nil(t, runningEvent.taskChange.AgentResourcePressure)
+}
+
+func TestSuppressionReason(t *testing.T) {
+	task := &apitask.Task{SentStatusUnsafe: apitaskstatus.TaskStopped}
+	regressiveEvent := newSendableTaskEvent(api.TaskStateChange{
+		Status: apitaskstatus.TaskRunning,
+		Task:   task,
+	})
+	assert.Equal(t, SuppressionReasonRegressive, regressiveEvent.suppressionReason())
+
+	redundantEvent := newSendableTaskEvent(api.TaskStateChange{
+		Status: apitaskstatus.TaskStopped,
+		Task:   task,
+	})
+	assert.Equal(t, SuppressionReasonRedundant, redundantEvent.suppressionReason())
+}
+
 func TestShouldTaskAttachmentEventBeSent(t *testing.T) {
 	for _, tc := range []struct {
 		event                  *sendableEvent
@@ -444,7 +469,9 @@ func TestSetContainerSentStatus(t *testing.T) {
 	testContainer := &apicontainer.Container{
 		Name:          testConainerName,
 		TaskARNUnsafe: testTaskARN,
+		ImageDigest:   "sha256:a",
 	}
+	testContainer.SetPulledImageDigest("sha256:b")
 
 	containerRunningStateChange := newSendableContainerEvent(api.ContainerStateChange{
 		Status:    apicontainerstatus.ContainerRunning,
@@ -457,6 +484,7 @@ func TestSetContainerSentStatus(t *testing.T) {
 
 	setContainerChangeSent(containerStoppedStateChange, dataClient)
 	assert.Equal(t, testContainer.GetSentStatus(), apicontainerstatus.ContainerStopped)
+	assert.Empty(t, testContainer.GetExpectedImageDigest())
 	setContainerChangeSent(containerRunningStateChange, dataClient)
 	assert.Equal(t, testContainer.GetSentStatus(), apicontainerstatus.ContainerStopped)
 
@@ -499,3 +527,23 @@ func newTestDataClient(t *testing.T) data.Client {
 	})
 	return testClient
 }
+
+func TestIsThrottleError(t *testing.T) {
+	throttle := awserr.New("ThrottlingException", "Rate exceeded", nil)
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"throttling error", throttle, true},
+		{"wrapped retriable throttling error", apierrors.NewRetriableError(apierrors.NewRetriable(true), throttle), true},
+		{"wrapped throttling error", fmt.Errorf("submit: %w", throttle), true},
+		{"other aws error", awserr.New("InvalidParameterException", "invalid", nil), false},
+		{"non aws error", errors.New("test"), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isThrottleError(tc.err))
+		})
+	}
+}
@@ -13,6 +13,8 @@
 
 package statechange
 
+import "time"
+
 const (
 	// ContainerEvent is used to define the container state transition events
 	// emitted by the engine
@@ -41,3 +43,47 @@ type Event interface {
 	// identify the type of event being emitted
 	GetEventType() EventType
 }
+
+const (
+	// MetricsKindContainer identifies the submission of a container state change
+	MetricsKindContainer = "container"
+	// MetricsKindTask identifies the submission of a task state change
+	MetricsKindTask = "task"
+	// MetricsKindAttachment identifies the submission of an attachment state change
+	MetricsKindAttachment = "attachment"
+)
+
+// MetricsEmitter receives metrics about the submission of state changes to ECS. The
+// kind of the state change is one of the MetricsKind constants above.
+type MetricsEmitter interface {
+	// IncStateChangeSubmitted counts a state change that was successfully submitted
+	IncStateChangeSubmitted(kind string)
+	// ObserveSubmitLatency records the duration of a submission attempt, whether it
+	// succeeded or not
+	ObserveSubmitLatency(kind string, d time.Duration)
+	// ObserveQueueDelay records the time a successfully submitted state change spent
+	// queued in the agent, and whether its submission was delayed by throttling
+	ObserveQueueDelay(kind string, d time.Duration, delayedByThrottling bool)
+}
+
+// EmitSubmission reports a submission attempt of the given kind that started at the
+// given time to the emitter. It's a no-op when the emitter is nil.
+func EmitSubmission(emitter MetricsEmitter, kind string, startedAt time.Time, err error) {
+	if emitter == nil {
+		return
+	}
+	emitter.ObserveSubmitLatency(kind, time.Since(startedAt))
+	if err == nil {
+		emitter.IncStateChangeSubmitted(kind)
+	}
+}
+
+// EmitQueueDelay reports the time a successfully submitted state change of the given kind
+// spent queued since the given time to the emitter. It's a no-op when the emitter is nil
+// or the time the change was queued is unknown.
+func EmitQueueDelay(emitter MetricsEmitter, kind string, queuedAt time.Time, delayedByThrottling bool) {
+	if emitter == nil || queuedAt.IsZero() {
+		return
+	}
+	emitter.ObserveQueueDelay(kind, time.Since(queuedAt), delayedByThrottling)
+}
@@ -192,6 +192,9 @@ func (container *StatsContainer) processStatsStream() error {
 				seelog.Warnf("Container [%s]: error converting stats for container: %v", dockerID, err)
 				continue
 			}
+			if apiContainer != nil {
+				apiContainer.SetLastStats(rawStat)
+			}
 			if isFirstStatAfterContainerRestart {
 				err = container.saveRestartAggregationData(apiContainer)
 				if err != nil {
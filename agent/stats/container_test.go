@@ -228,6 +228,7 @@ func TestContainerStatsCollection_WithRestartPolicy(t *testing.T) {
 	restartStatSet, err := container.statsQueue.GetRestartStatsSet()
 	require.NoError(t, err)
 	require.Equal(t, int64(numStatsPreRestart), *restartStatSet.RestartCount)
+	require.NotNil(t, mockContainer.Container.GetLastStats())
 	// Reset sets all of the existing stats to "sent" status in the stats queue
 	container.statsQueue.Reset()
 
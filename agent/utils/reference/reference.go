@@ -116,3 +116,29 @@ func DigestExists(imageRef string) bool {
 	_, ok := parsedImageRef.(reference.Digested)
 	return ok
 }
+
+// publicRegistryHosts are the hosts of well known public container registries.
+var publicRegistryHosts = map[string]struct{}{
+	"docker.io":       {},
+	"quay.io":         {},
+	"ghcr.io":         {},
+	"gcr.io":          {},
+	"registry.k8s.io": {},
+	"public.ecr.aws":  {},
+}
+
+// GetRegistryHost returns the registry host of an image reference. Image references
+// without an explicit registry resolve to Docker Hub.
+func GetRegistryHost(imageRef string) (string, error) {
+	namedRef, err := reference.ParseNormalizedNamed(imageRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse image reference '%s': %w", imageRef, err)
+	}
+	return reference.Domain(namedRef), nil
+}
+
+// IsPublicRegistryHost checks if the registry host is a well known public registry.
+func IsPublicRegistryHost(host string) bool {
+	_, ok := publicRegistryHosts[host]
+	return ok
+}
@@ -292,3 +292,38 @@ func TestDigestExists(t *testing.T) {
 		})
 	}
 }
+
+func TestGetRegistryHost(t *testing.T) {
+	tcs := []struct {
+		imageRef     string
+		expectedHost string
+		expectError  bool
+	}{
+		{imageRef: "ubuntu", expectedHost: "docker.io"},
+		{imageRef: "quay.io/prometheus/prometheus:latest", expectedHost: "quay.io"},
+		{imageRef: "public.ecr.aws/library/alpine", expectedHost: "public.ecr.aws"},
+		{
+			imageRef:     "123456789012.dkr.ecr.us-west-2.amazonaws.com/app:1",
+			expectedHost: "123456789012.dkr.ecr.us-west-2.amazonaws.com",
+		},
+		{imageRef: "invalid imageRef", expectError: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.imageRef, func(t *testing.T) {
+			host, err := GetRegistryHost(tc.imageRef)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedHost, host)
+		})
+	}
+}
+
+func TestIsPublicRegistryHost(t *testing.T) {
+	assert.True(t, IsPublicRegistryHost("docker.io"))
+	assert.True(t, IsPublicRegistryHost("public.ecr.aws"))
+	assert.False(t, IsPublicRegistryHost("123456789012.dkr.ecr.us-west-2.amazonaws.com"))
+	assert.False(t, IsPublicRegistryHost("registry.example.com"))
+}
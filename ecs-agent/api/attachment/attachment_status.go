@@ -31,6 +31,14 @@ var attachmentStatusMap = map[string]AttachmentStatus{
 	"DETACHED": AttachmentDetached,
 }
 
+// attachmentStatusTransitions lists the statuses an attachment can move to from each status.
+// An attachment is attached and later detached, and never goes back to an earlier status.
+var attachmentStatusTransitions = map[AttachmentStatus][]AttachmentStatus{
+	AttachmentNone:     {AttachmentAttached, AttachmentDetached},
+	AttachmentAttached: {AttachmentDetached},
+	AttachmentDetached: {},
+}
+
 // String return the string value of the attachment status
 func (attachStatus *AttachmentStatus) String() string {
 	for k, v := range attachmentStatusMap {
@@ -45,3 +53,21 @@ func (attachStatus *AttachmentStatus) String() string {
 func (attachStatus *AttachmentStatus) ShouldSend() bool {
 	return *attachStatus == AttachmentAttached
 }
+
+// CanTransitionTo returns whether next is a legal successor of the status in the attachment
+// lifecycle. Staying in the same status is allowed. It returns false for unknown statuses.
+func (attachStatus *AttachmentStatus) CanTransitionTo(next AttachmentStatus) bool {
+	successors, ok := attachmentStatusTransitions[*attachStatus]
+	if !ok {
+		return false
+	}
+	if next == *attachStatus {
+		return true
+	}
+	for _, successor := range successors {
+		if next == successor {
+			return true
+		}
+	}
+	return false
+}
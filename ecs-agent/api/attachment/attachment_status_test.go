@@ -77,3 +77,29 @@ func TestShouldSend(t *testing.T) {
 		})
 	}
 }
+
+func TestCanTransitionTo(t *testing.T) {
+	testCases := []struct {
+		from  AttachmentStatus
+		to    AttachmentStatus
+		legal bool
+	}{
+		{from: AttachmentNone, to: AttachmentNone, legal: true},
+		{from: AttachmentNone, to: AttachmentAttached, legal: true},
+		{from: AttachmentNone, to: AttachmentDetached, legal: true},
+		{from: AttachmentAttached, to: AttachmentNone, legal: false},
+		{from: AttachmentAttached, to: AttachmentAttached, legal: true},
+		{from: AttachmentAttached, to: AttachmentDetached, legal: true},
+		{from: AttachmentDetached, to: AttachmentNone, legal: false},
+		{from: AttachmentDetached, to: AttachmentAttached, legal: false},
+		{from: AttachmentDetached, to: AttachmentDetached, legal: true},
+		{from: AttachmentStatus(42), to: AttachmentAttached, legal: false},
+		{from: AttachmentStatus(42), to: AttachmentStatus(42), legal: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run((&tc.from).String()+" to "+(&tc.to).String(), func(t *testing.T) {
+			assert.Equal(t, tc.legal, (&tc.from).CanTransitionTo(tc.to))
+		})
+	}
+}
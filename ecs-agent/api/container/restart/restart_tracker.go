@@ -21,11 +21,16 @@ import (
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 )
 
+// maxRecentRestartReasons is the maximum number of restart reasons kept by the tracker.
+const maxRecentRestartReasons = 5
+
 type RestartTracker struct {
 	RestartCount  int           `json:"restartCount,omitempty"`
 	LastRestartAt time.Time     `json:"lastRestartAt,omitempty"`
 	RestartPolicy RestartPolicy `json:"restartPolicy,omitempty"`
-	lock          sync.RWMutex
+	// RecentRestartReasons are the exit reasons of the most recent restarts, most recent first.
+	RecentRestartReasons []string `json:"recentRestartReasons,omitempty"`
+	lock                 sync.RWMutex
 }
 
 // RestartPolicy represents a policy that contains key information considered when
@@ -65,6 +70,33 @@ func (rt *RestartTracker) RecordRestart() {
 	rt.LastRestartAt = time.Now()
 }
 
+// RecordRestartReason records the exit code and reason of the exit that caused a restart.
+// Only the most recent reasons are kept.
+func (rt *RestartTracker) RecordRestartReason(exitCode *int, reason string) {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	entry := "exit code unknown"
+	if exitCode != nil {
+		entry = fmt.Sprintf("exit code %d", *exitCode)
+	}
+	if reason != "" {
+		entry += ": " + reason
+	}
+	rt.RecentRestartReasons = append([]string{entry}, rt.RecentRestartReasons...)
+	if len(rt.RecentRestartReasons) > maxRecentRestartReasons {
+		rt.RecentRestartReasons = rt.RecentRestartReasons[:maxRecentRestartReasons]
+	}
+}
+
+// GetRecentRestartReasons returns the exit reasons of the most recent restarts, most recent first.
+func (rt *RestartTracker) GetRecentRestartReasons() []string {
+	rt.lock.RLock()
+	defer rt.lock.RUnlock()
+
+	return append([]string(nil), rt.RecentRestartReasons...)
+}
+
 // ShouldRestart returns whether the container should restart and a reason string
 // explaining why not. The reset attempt period will be calculated first
 // with LastRestart at, using the passed in startedAt if it does not exist.
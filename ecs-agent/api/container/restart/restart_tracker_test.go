@@ -175,6 +175,28 @@ func TestRecordRestart(t *testing.T) {
 	}
 }
 
+func TestRecordRestartReason(t *testing.T) {
+	rt := NewRestartTracker(RestartPolicy{
+		Enabled:              true,
+		RestartAttemptPeriod: 60,
+	})
+	assert.Empty(t, rt.GetRecentRestartReasons())
+
+	exitCode := 1
+	rt.RecordRestartReason(nil, "")
+	rt.RecordRestartReason(&exitCode, "")
+	rt.RecordRestartReason(&exitCode, "out of memory")
+	assert.Equal(t, []string{"exit code 1: out of memory", "exit code 1", "exit code unknown"},
+		rt.GetRecentRestartReasons())
+
+	for i := 0; i < 2*maxRecentRestartReasons; i++ {
+		rt.RecordRestartReason(&i, "")
+	}
+	reasons := rt.GetRecentRestartReasons()
+	assert.Len(t, reasons, maxRecentRestartReasons)
+	assert.Equal(t, fmt.Sprintf("exit code %d", 2*maxRecentRestartReasons-1), reasons[0])
+}
+
 func TestRecordRestartPolicy(t *testing.T) {
 	rt := NewRestartTracker(RestartPolicy{
 		Enabled:              false,
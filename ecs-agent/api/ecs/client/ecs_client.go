@@ -21,6 +21,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
 	ecsmodel "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/model/ecs"
@@ -43,10 +44,10 @@ import (
 )
 
 const (
-	ecsMaxImageDigestLength     = 255
-	ecsMaxContainerReasonLength = 255
+	ecsMaxImageDigestLength     = ecs.MaxImageDigestLength
+	ecsMaxContainerReasonLength = ecs.MaxContainerReasonLength
 	ecsMaxTaskReasonLength      = 1024
-	ecsMaxRuntimeIDLength       = 255
+	ecsMaxRuntimeIDLength       = ecs.MaxRuntimeIDLength
 	defaultPollEndpointCacheTTL = 12 * time.Hour
 	azAttrName                  = "ecs.availability-zone"
 	cpuArchAttrName             = "ecs.cpu-architecture"
@@ -74,6 +75,7 @@ type ecsClient struct {
 	pollEndpointCache                async.TTLCache
 	isFIPSDetected                   bool
 	shouldExcludeIPv6PortBinding     bool
+	stateChangeDryRun                bool
 	sascCustomRetryBackoff           func(func() error) error
 	stscAttachmentCustomRetryBackoff func(func() error) error
 }
@@ -492,6 +494,20 @@ func (client *ecsClient) getCustomAttributes() []*ecsmodel.Attribute {
 }
 
 func (client *ecsClient) SubmitTaskStateChange(change ecs.TaskStateChange) error {
+	if ok, err := change.Submittable(); !ok {
+		if errors.Is(err, ecs.ErrStatusRegression) {
+			logger.Warn("Not submitting task state change that regresses the sent status", logger.Fields{
+				field.TaskARN:     change.TaskARN,
+				"taskStateChange": change.String(),
+			})
+			return err
+		}
+		logger.Error("Not submitting invalid task state change", logger.Fields{
+			field.Error:       err,
+			"taskStateChange": change.String(),
+		})
+		return err
+	}
 	if change.Attachment != nil && client.stscAttachmentCustomRetryBackoff != nil {
 		retryFunc := func() error {
 			err := client.submitTaskStateChange(change)
@@ -523,6 +539,10 @@ func (client *ecsClient) submitTaskStateChange(change ecs.TaskStateChange) error
 			},
 		}
 
+		if client.stateChangeDryRun {
+			logDryRun("taskStateChange", change.String())
+			return nil
+		}
 		_, err := client.submitStateChangeClient.SubmitTaskStateChange(&ecsmodel.SubmitTaskStateChangeInput{
 			Cluster:     aws.String(clusterARN),
 			Task:        aws.String(change.TaskARN),
@@ -553,6 +573,10 @@ func (client *ecsClient) submitTaskStateChange(change ecs.TaskStateChange) error
 		Containers:         formatContainers(change.Containers, client.shouldExcludeIPv6PortBinding, change.TaskARN),
 	}
 
+	if client.stateChangeDryRun {
+		logDryRun("taskStateChange", change.String())
+		return nil
+	}
 	_, err := client.submitStateChangeClient.SubmitTaskStateChange(&req)
 	if err != nil {
 		logger.Error("Could not submit task state change", logger.Fields{
@@ -566,47 +590,53 @@ func (client *ecsClient) submitTaskStateChange(change ecs.TaskStateChange) error
 }
 
 func (client *ecsClient) SubmitContainerStateChange(change ecs.ContainerStateChange) error {
+	wire := change.ToWire()
 
-	input := ecsmodel.SubmitContainerStateChangeInput{
-		Cluster:       aws.String(client.configAccessor.Cluster()),
-		ContainerName: aws.String(change.ContainerName),
-		Task:          aws.String(change.TaskArn),
-	}
-
-	if change.RuntimeID != "" {
-		input.RuntimeId = aws.String(trimString(change.RuntimeID, ecsMaxRuntimeIDLength))
-	}
-
-	if change.Reason != "" {
-		input.Reason = aws.String(trimString(change.Reason, ecsMaxContainerReasonLength))
-	}
-
-	stat := change.Status.String()
-	if stat == "DEAD" {
-		stat = apicontainerstatus.ContainerStopped.String()
-	}
+	stat := aws.StringValue(wire.Status)
 	if stat != apicontainerstatus.ContainerStopped.String() && stat != apicontainerstatus.ContainerRunning.String() {
 		logger.Info("Not submitting unsupported upstream container state", logger.Fields{
 			field.ContainerName: change.ContainerName,
-			field.Status:        stat,
+			field.Status:        change.Status.String(),
 			field.TaskARN:       change.TaskArn,
 		})
 		return nil
 	}
-	input.Status = aws.String(stat)
 
-	if change.ExitCode != nil {
-		exitCode := int64(aws.IntValue(change.ExitCode))
-		input.ExitCode = aws.Int64(exitCode)
+	if ok, err := change.Submittable(); !ok {
+		if errors.Is(err, ecs.ErrStatusRegression) {
+			logger.Warn("Not submitting container state change that regresses the sent status", logger.Fields{
+				field.TaskARN:          change.TaskArn,
+				"containerStateChange": change.String(),
+			})
+			return err
+		}
+		logger.Error("Not submitting invalid container state change", logger.Fields{
+			field.Error:            err,
+			field.TaskARN:          change.TaskArn,
+			"containerStateChange": change.String(),
+		})
+		return err
 	}
 
-	networkBindings := change.NetworkBindings
+	input := ecsmodel.SubmitContainerStateChangeInput{
+		Cluster:         aws.String(client.configAccessor.Cluster()),
+		Task:            aws.String(change.TaskArn),
+		ContainerName:   wire.ContainerName,
+		RuntimeId:       wire.RuntimeId,
+		Reason:          wire.Reason,
+		Status:          wire.Status,
+		ExitCode:        wire.ExitCode,
+		NetworkBindings: wire.NetworkBindings,
+	}
 	if client.shouldExcludeIPv6PortBinding {
-		networkBindings = excludeIPv6PortBindingFromNetworkBindings(networkBindings, change.ContainerName,
-			change.TaskArn)
+		input.NetworkBindings = excludeIPv6PortBindingFromNetworkBindings(input.NetworkBindings,
+			change.ContainerName, change.TaskArn)
 	}
-	input.NetworkBindings = networkBindings
 
+	if client.stateChangeDryRun {
+		logDryRun("containerStateChange", change.String())
+		return nil
+	}
 	_, err := client.submitStateChangeClient.SubmitContainerStateChange(&input)
 	if err != nil {
 		logger.Error("Could not submit container state change", logger.Fields{
@@ -620,6 +650,12 @@ func (client *ecsClient) SubmitContainerStateChange(change ecs.ContainerStateCha
 }
 
 func (client *ecsClient) SubmitAttachmentStateChange(change ecs.AttachmentStateChange) error {
+	if ok, err := change.Submittable(); !ok {
+		logger.Warn("Not submitting invalid attachment state change", logger.Fields{
+			field.Error: err,
+		})
+		return err
+	}
 	if client.sascCustomRetryBackoff != nil {
 		retryFunc := func() error {
 			err := client.submitAttachmentStateChange(change)
@@ -634,23 +670,58 @@ func (client *ecsClient) SubmitAttachmentStateChange(change ecs.AttachmentStateC
 }
 
 func (client *ecsClient) submitAttachmentStateChange(change ecs.AttachmentStateChange) error {
-	attachmentStatus := change.Attachment.GetAttachmentStatus()
+	return client.submitAttachmentStatus(change.Attachment.GetAttachmentARN(),
+		change.Attachment.GetAttachmentStatus(), change.String())
+}
 
+// SubmitVolumeAttachmentStateChange submits the status of a volume attachment, such as an
+// EBS volume attached to a task, the same way as that of other attachments.
+func (client *ecsClient) SubmitVolumeAttachmentStateChange(change ecs.VolumeAttachmentStateChange) error {
+	if ok, err := change.Submittable(); !ok {
+		logger.Warn("Not submitting invalid volume attachment state change", logger.Fields{
+			field.Error: err,
+		})
+		return err
+	}
+	submit := func() error {
+		return client.submitAttachmentStatus(change.AttachmentARN, change.Status, change.String())
+	}
+	if client.sascCustomRetryBackoff != nil {
+		retryFunc := func() error {
+			err := submit()
+			if err == nil {
+				return nil
+			}
+			return submitStateCustomRetriableError(err)
+		}
+		return client.sascCustomRetryBackoff(retryFunc)
+	}
+	return submit()
+}
+
+// submitAttachmentStatus submits the status of the attachment with the given ARN. The string
+// representation of the state change is only used for logging.
+func (client *ecsClient) submitAttachmentStatus(attachmentARN string, attachmentStatus attachment.AttachmentStatus,
+	changeString string) error {
 	req := ecsmodel.SubmitAttachmentStateChangesInput{
 		Cluster: aws.String(client.configAccessor.Cluster()),
 		Attachments: []*ecsmodel.AttachmentStateChange{
 			{
-				AttachmentArn: aws.String(change.Attachment.GetAttachmentARN()),
+				AttachmentArn: aws.String(attachmentARN),
 				Status:        aws.String(attachmentStatus.String()),
 			},
 		},
 	}
 
+	if client.stateChangeDryRun {
+		logDryRun("attachmentStateChange", changeString)
+		return nil
+	}
 	_, err := client.submitStateChangeClient.SubmitAttachmentStateChanges(&req)
 	if err != nil {
 		logger.Warn("Could not submit attachment state change", logger.Fields{
 			field.Error:             err,
-			"attachmentStateChange": change.String(),
+			"attachmentStateChange": changeString,
 		})
 		return err
 	}
@@ -658,6 +729,14 @@ func (client *ecsClient) submitAttachmentStateChange(change ecs.AttachmentStateC
 	return nil
 }
 
+// logDryRun logs the state change that would have been submitted if the client wasn't in
+// dry-run mode.
+func logDryRun(changeField, changeString string) {
+	logger.Info("Dry run: not submitting state change", logger.Fields{
+		changeField: changeString,
+	})
+}
+
 func submitStateCustomRetriableError(err error) error {
 	retry := true
 	aerr, ok := err.(awserr.Error)
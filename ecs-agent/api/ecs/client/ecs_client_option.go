@@ -45,6 +45,16 @@ func WithIPv6PortBindingExcluded(val bool) ECSClientOption {
 	}
 }
 
+// WithStateChangeDryRun is an ECSClientOption that configures the
+// ecsClient.stateChangeDryRun with the value passed as a parameter. In dry-run mode,
+// the state changes are validated and converted as usual, but only logged instead of
+// being submitted.
+func WithStateChangeDryRun(val bool) ECSClientOption {
+	return func(client *ecsClient) {
+		client.stateChangeDryRun = val
+	}
+}
+
 // WithSASCCustomRetryBackoff is an ECSClientOption that configures the
 // ecsClient.sascCustomRetryBackoff with the value passed as a parameter.
 func WithSASCCustomRetryBackoff(f func(func() error) error) ECSClientOption {
@@ -59,6 +59,13 @@ func TestWithIPv6PortBindingExcluded(t *testing.T) {
 	assert.False(t, client.shouldExcludeIPv6PortBinding)
 }
 
+func TestWithStateChangeDryRun(t *testing.T) {
+	client := &ecsClient{} // client.stateChangeDryRun is false by default
+	option := WithStateChangeDryRun(true)
+	option(client)
+	assert.True(t, client.stateChangeDryRun)
+}
+
 func TestWithSASCCustomRetryBackoff(t *testing.T) {
 	client := &ecsClient{} // client.sascCustomRetryBackoff is nil by default
 	option := WithSASCCustomRetryBackoff(callFnAndReturnNilFunc)
@@ -27,6 +27,7 @@ import (
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
 	mock_ecs "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/mocks"
+	mock_statechange "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/mocks/statechange"
 	ecsmodel "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/model/ecs"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/async"
@@ -239,6 +240,28 @@ func TestSubmitContainerStateChangeFull(t *testing.T) {
 	assert.NoError(t, err, "Unable to submit container state change")
 }
 
+func TestSubmitContainerStateChangeInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tester := setup(t, ctrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+
+	exitCode := 20
+
+	// The invalid state change must not be submitted
+	tester.mockSubmitStateClient.EXPECT().SubmitContainerStateChange(gomock.Any()).Times(0)
+	err := tester.client.SubmitContainerStateChange(ecs.ContainerStateChange{
+		TaskArn:       taskARN,
+		ContainerName: containerName,
+		RuntimeID:     runtimeID,
+		Status:        apicontainerstatus.ContainerRunning,
+		ExitCode:      &exitCode,
+	})
+
+	var validationErr *ecs.StateChangeValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "ExitCode", validationErr.Field)
+}
+
 func TestSubmitContainerStateChangeReason(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -298,6 +321,61 @@ func TestSubmitContainerStateChangeLongReason(t *testing.T) {
 	assert.NoError(t, err, "Unable to submit container state change")
 }
 
+func TestSubmitContainerStateChangeMatchesToWire(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tester := setup(t, ctrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+
+	exitCode := 20
+	change := ecs.ContainerStateChange{
+		TaskArn:       taskARN,
+		ContainerName: containerName,
+		RuntimeID:     strings.Repeat("r", ecsMaxRuntimeIDLength+1),
+		Status:        apicontainerstatus.ContainerStopped,
+		ExitCode:      &exitCode,
+		Reason:        strings.Repeat("a", ecsMaxContainerReasonLength+1),
+		NetworkBindings: []*ecsmodel.NetworkBinding{
+			{
+				BindIP:        aws.String("1.2.3.4"),
+				ContainerPort: aws.Int64(1),
+				HostPort:      aws.Int64(2),
+				Protocol:      aws.String("tcp"),
+			},
+		},
+	}
+	wire := change.ToWire()
+
+	tester.mockSubmitStateClient.EXPECT().SubmitContainerStateChange(&ecsmodel.SubmitContainerStateChangeInput{
+		Cluster:         aws.String(configuredCluster),
+		Task:            aws.String(taskARN),
+		ContainerName:   wire.ContainerName,
+		RuntimeId:       wire.RuntimeId,
+		Status:          wire.Status,
+		ExitCode:        wire.ExitCode,
+		Reason:          wire.Reason,
+		NetworkBindings: wire.NetworkBindings,
+	})
+	assert.NoError(t, tester.client.SubmitContainerStateChange(change))
+	assert.Len(t, aws.StringValue(wire.RuntimeId), ecsMaxRuntimeIDLength)
+	assert.Len(t, aws.StringValue(wire.Reason), ecsMaxContainerReasonLength)
+}
+
+func TestSubmitContainerStateChangeUnsupportedStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tester := setup(t, ctrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+
+	// Containers are only reported to ECS as RUNNING or STOPPED
+	tester.mockSubmitStateClient.EXPECT().SubmitContainerStateChange(gomock.Any()).Times(0)
+	err := tester.client.SubmitContainerStateChange(ecs.ContainerStateChange{
+		TaskArn:       taskARN,
+		ContainerName: containerName,
+		RuntimeID:     runtimeID,
+		Status:        apicontainerstatus.ContainerCreated,
+	})
+	assert.NoError(t, err)
+}
+
 func buildAttributeList(capabilities []string, attributes map[string]string) []*ecsmodel.Attribute {
 	var rv []*ecsmodel.Attribute
 	for _, capability := range capabilities {
@@ -976,6 +1054,36 @@ func TestSubmitTaskStateChangeWithAttachments(t *testing.T) {
 	assert.NoError(t, err, "Unable to submit task state change with attachments")
 }
 
+// TestSubmitTaskStateChangeWithAttachmentsInvalid tests that a task state change confirming an
+// attachment is validated before it is submitted.
+func TestSubmitTaskStateChangeWithAttachmentsInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	retries := 0
+	tester := setup(t, ctrl, ec2.NewBlackholeEC2MetadataClient(), nil,
+		WithSTSCAttachmentCustomRetryBackoff(func(f func() error) error {
+			retries++
+			return f()
+		}))
+	// The invalid state change must neither be submitted nor retried
+	tester.mockSubmitStateClient.EXPECT().SubmitTaskStateChange(gomock.Any()).Times(0)
+
+	err := tester.client.SubmitTaskStateChange(ecs.TaskStateChange{
+		Attachment: &ni.ENIAttachment{
+			AttachmentInfo: attachment.AttachmentInfo{
+				AttachmentARN: attachmentARN,
+				Status:        attachment.AttachmentAttached,
+			},
+		},
+	})
+
+	var validationErr *ecs.StateChangeValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "TaskARN", validationErr.Field)
+	assert.Zero(t, retries)
+}
+
 func TestSubmitTaskStateChangeWithoutAttachments(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -995,6 +1103,52 @@ func TestSubmitTaskStateChangeWithoutAttachments(t *testing.T) {
 	assert.NoError(t, err, "Unable to submit task state change with no attachments")
 }
 
+func TestSubmitTaskStateChangeStatusRegression(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tester := setup(t, ctrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+	metadataGetter := mock_statechange.NewMockTaskMetadataGetter(ctrl)
+	metadataGetter.EXPECT().GetTaskIsNil().Return(false).AnyTimes()
+	metadataGetter.EXPECT().GetTaskKnownStatus().Return(apitaskstatus.TaskRunning.String()).AnyTimes()
+	metadataGetter.EXPECT().GetTaskSentStatusString().Return(apitaskstatus.TaskStopped.String()).AnyTimes()
+	metadataGetter.EXPECT().GetTaskPullStartedAt().Return(time.Time{}).AnyTimes()
+	metadataGetter.EXPECT().GetTaskPullStoppedAt().Return(time.Time{}).AnyTimes()
+	metadataGetter.EXPECT().GetTaskExecutionStoppedAt().Return(time.Time{}).AnyTimes()
+	// No SubmitTaskStateChange call is expected.
+
+	err := tester.client.SubmitTaskStateChange(ecs.TaskStateChange{
+		TaskARN:        taskARN,
+		Status:         apitaskstatus.TaskRunning,
+		MetadataGetter: metadataGetter,
+	})
+	assert.ErrorIs(t, err, ecs.ErrStatusRegression)
+}
+
+func TestSubmitContainerStateChangeStatusRegression(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tester := setup(t, ctrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+	metadataGetter := mock_statechange.NewMockContainerMetadataGetter(ctrl)
+	metadataGetter.EXPECT().GetContainerIsNil().Return(false).AnyTimes()
+	metadataGetter.EXPECT().GetContainerSentStatusString().Return(apicontainerstatus.ContainerStopped.String()).AnyTimes()
+	metadataGetter.EXPECT().GetContainerRuntimeID().Return(runtimeID).AnyTimes()
+	metadataGetter.EXPECT().GetContainerIsEssential().Return(true).AnyTimes()
+	metadataGetter.EXPECT().GetContainerImageName().Return("").AnyTimes()
+	metadataGetter.EXPECT().GetContainerNetworkMode().Return("").AnyTimes()
+	// No SubmitContainerStateChange call is expected.
+
+	err := tester.client.SubmitContainerStateChange(ecs.ContainerStateChange{
+		TaskArn:        taskARN,
+		ContainerName:  containerName,
+		RuntimeID:      runtimeID,
+		Status:         apicontainerstatus.ContainerRunning,
+		MetadataGetter: metadataGetter,
+	})
+	assert.ErrorIs(t, err, ecs.ErrStatusRegression)
+}
+
 func TestSubmitTaskStateChangeWithManagedAgents(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1099,6 +1253,107 @@ func TestSubmitAttachmentStateChange(t *testing.T) {
 	assert.NoError(t, err, "Unable to submit attachment state change")
 }
 
+func TestSubmitStateChangeDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tester := setup(t, ctrl, ec2.NewBlackholeEC2MetadataClient(), nil, WithStateChangeDryRun(true))
+
+	// No state change must be submitted in dry-run mode.
+	tester.mockSubmitStateClient.EXPECT().SubmitTaskStateChange(gomock.Any()).Times(0)
+	tester.mockSubmitStateClient.EXPECT().SubmitContainerStateChange(gomock.Any()).Times(0)
+	tester.mockSubmitStateClient.EXPECT().SubmitAttachmentStateChanges(gomock.Any()).Times(0)
+
+	err := tester.client.SubmitTaskStateChange(ecs.TaskStateChange{
+		TaskARN: taskARN,
+		Status:  apitaskstatus.TaskRunning,
+	})
+	assert.NoError(t, err)
+
+	err = tester.client.SubmitContainerStateChange(ecs.ContainerStateChange{
+		TaskArn:       taskARN,
+		ContainerName: containerName,
+		RuntimeID:     runtimeID,
+		Status:        apicontainerstatus.ContainerRunning,
+	})
+	assert.NoError(t, err)
+
+	err = tester.client.SubmitAttachmentStateChange(ecs.AttachmentStateChange{
+		Attachment: &ni.ENIAttachment{
+			AttachmentInfo: attachment.AttachmentInfo{
+				AttachmentARN: attachmentARN,
+				Status:        attachment.AttachmentAttached,
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	// The state changes are still validated.
+	err = tester.client.SubmitTaskStateChange(ecs.TaskStateChange{Status: apitaskstatus.TaskRunning})
+	assert.Error(t, err)
+}
+
+func TestSubmitAttachmentStateChangeInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tester := setup(t, ctrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+
+	// The invalid state changes must not be submitted
+	tester.mockSubmitStateClient.EXPECT().SubmitAttachmentStateChanges(gomock.Any()).Times(0)
+
+	err := tester.client.SubmitAttachmentStateChange(ecs.AttachmentStateChange{})
+	assert.ErrorIs(t, err, ecs.ErrEmptyAttachmentStateChange)
+
+	err = tester.client.SubmitAttachmentStateChange(ecs.AttachmentStateChange{
+		Attachment: &ni.ENIAttachment{
+			AttachmentInfo: attachment.AttachmentInfo{
+				Status: attachment.AttachmentAttached,
+			},
+		},
+	})
+	assert.ErrorIs(t, err, ecs.ErrEmptyAttachmentStateChange)
+}
+
+func TestSubmitVolumeAttachmentStateChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tester := setup(t, ctrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+
+	tester.mockSubmitStateClient.EXPECT().SubmitAttachmentStateChanges(&ecsmodel.SubmitAttachmentStateChangesInput{
+		Cluster: aws.String(configuredCluster),
+		Attachments: []*ecsmodel.AttachmentStateChange{
+			{
+				AttachmentArn: aws.String(attachmentARN),
+				Status:        aws.String("ATTACHED"),
+			},
+		},
+	})
+	err := tester.client.SubmitVolumeAttachmentStateChange(ecs.VolumeAttachmentStateChange{
+		AttachmentARN: attachmentARN,
+		VolumeID:      "vol-12345",
+		Status:        attachment.AttachmentAttached,
+	})
+
+	assert.NoError(t, err, "Unable to submit volume attachment state change")
+}
+
+func TestSubmitVolumeAttachmentStateChangeInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tester := setup(t, ctrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+
+	// The invalid state changes must not be submitted
+	tester.mockSubmitStateClient.EXPECT().SubmitAttachmentStateChanges(gomock.Any()).Times(0)
+
+	err := tester.client.SubmitVolumeAttachmentStateChange(ecs.VolumeAttachmentStateChange{VolumeID: "vol-12345"})
+	assert.ErrorIs(t, err, ecs.ErrEmptyAttachmentStateChange)
+
+	err = tester.client.SubmitVolumeAttachmentStateChange(ecs.VolumeAttachmentStateChange{
+		AttachmentARN: attachmentARN,
+		Status:        attachment.AttachmentAttached,
+	})
+	assert.Error(t, err)
+}
+
 func TestSubmitAttachmentStateChangeWithRetriableError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -42,6 +42,9 @@ type ECSClient interface {
 	// SubmitAttachmentStateChange sends an attachment state change and returns an error
 	// indicating if it was submitted
 	SubmitAttachmentStateChange(change AttachmentStateChange) error
+	// SubmitVolumeAttachmentStateChange sends a volume attachment state change and returns
+	// an error indicating if it was submitted
+	SubmitVolumeAttachmentStateChange(change VolumeAttachmentStateChange) error
 	// DiscoverPollEndpoint takes a ContainerInstanceARN and returns the
 	// endpoint at which this Agent should contact ACS
 	DiscoverPollEndpoint(containerInstanceArn string) (string, error)
@@ -350,6 +350,20 @@ func (mr *MockECSClientMockRecorder) SubmitTaskStateChange(arg0 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitTaskStateChange", reflect.TypeOf((*MockECSClient)(nil).SubmitTaskStateChange), arg0)
 }
 
+// SubmitVolumeAttachmentStateChange mocks base method.
+func (m *MockECSClient) SubmitVolumeAttachmentStateChange(arg0 ecs.VolumeAttachmentStateChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitVolumeAttachmentStateChange", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SubmitVolumeAttachmentStateChange indicates an expected call of SubmitVolumeAttachmentStateChange.
+func (mr *MockECSClientMockRecorder) SubmitVolumeAttachmentStateChange(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitVolumeAttachmentStateChange", reflect.TypeOf((*MockECSClient)(nil).SubmitVolumeAttachmentStateChange), arg0)
+}
+
 // UpdateContainerInstancesState mocks base method.
 func (m *MockECSClient) UpdateContainerInstancesState(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
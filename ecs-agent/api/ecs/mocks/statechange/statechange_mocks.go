@@ -22,6 +22,7 @@ import (
 	reflect "reflect"
 	time "time"
 
+	status "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -48,6 +49,90 @@ func (m *MockContainerMetadataGetter) EXPECT() *MockContainerMetadataGetterMockR
 	return m.recorder
 }
 
+// GetContainerAssignedDevices mocks base method.
+func (m *MockContainerMetadataGetter) GetContainerAssignedDevices() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContainerAssignedDevices")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetContainerAssignedDevices indicates an expected call of GetContainerAssignedDevices.
+func (mr *MockContainerMetadataGetterMockRecorder) GetContainerAssignedDevices() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerAssignedDevices", reflect.TypeOf((*MockContainerMetadataGetter)(nil).GetContainerAssignedDevices))
+}
+
+// GetContainerCreatedAt mocks base method.
+func (m *MockContainerMetadataGetter) GetContainerCreatedAt() time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContainerCreatedAt")
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// GetContainerCreatedAt indicates an expected call of GetContainerCreatedAt.
+func (mr *MockContainerMetadataGetterMockRecorder) GetContainerCreatedAt() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerCreatedAt", reflect.TypeOf((*MockContainerMetadataGetter)(nil).GetContainerCreatedAt))
+}
+
+// GetContainerExitCode mocks base method.
+func (m *MockContainerMetadataGetter) GetContainerExitCode() *int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContainerExitCode")
+	ret0, _ := ret[0].(*int)
+	return ret0
+}
+
+// GetContainerExitCode indicates an expected call of GetContainerExitCode.
+func (mr *MockContainerMetadataGetterMockRecorder) GetContainerExitCode() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerExitCode", reflect.TypeOf((*MockContainerMetadataGetter)(nil).GetContainerExitCode))
+}
+
+// GetContainerHealthStatus mocks base method.
+func (m *MockContainerMetadataGetter) GetContainerHealthStatus() status.ContainerHealthStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContainerHealthStatus")
+	ret0, _ := ret[0].(status.ContainerHealthStatus)
+	return ret0
+}
+
+// GetContainerHealthStatus indicates an expected call of GetContainerHealthStatus.
+func (mr *MockContainerMetadataGetterMockRecorder) GetContainerHealthStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerHealthStatus", reflect.TypeOf((*MockContainerMetadataGetter)(nil).GetContainerHealthStatus))
+}
+
+// GetContainerImageDigest mocks base method.
+func (m *MockContainerMetadataGetter) GetContainerImageDigest() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContainerImageDigest")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetContainerImageDigest indicates an expected call of GetContainerImageDigest.
+func (mr *MockContainerMetadataGetterMockRecorder) GetContainerImageDigest() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerImageDigest", reflect.TypeOf((*MockContainerMetadataGetter)(nil).GetContainerImageDigest))
+}
+
+// GetContainerImageName mocks base method.
+func (m *MockContainerMetadataGetter) GetContainerImageName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContainerImageName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetContainerImageName indicates an expected call of GetContainerImageName.
+func (mr *MockContainerMetadataGetterMockRecorder) GetContainerImageName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerImageName", reflect.TypeOf((*MockContainerMetadataGetter)(nil).GetContainerImageName))
+}
+
 // GetContainerIsEssential mocks base method.
 func (m *MockContainerMetadataGetter) GetContainerIsEssential() bool {
 	m.ctrl.T.Helper()
@@ -76,6 +161,34 @@ func (mr *MockContainerMetadataGetterMockRecorder) GetContainerIsNil() *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerIsNil", reflect.TypeOf((*MockContainerMetadataGetter)(nil).GetContainerIsNil))
 }
 
+// GetContainerName mocks base method.
+func (m *MockContainerMetadataGetter) GetContainerName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContainerName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetContainerName indicates an expected call of GetContainerName.
+func (mr *MockContainerMetadataGetterMockRecorder) GetContainerName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerName", reflect.TypeOf((*MockContainerMetadataGetter)(nil).GetContainerName))
+}
+
+// GetContainerNetworkMode mocks base method.
+func (m *MockContainerMetadataGetter) GetContainerNetworkMode() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContainerNetworkMode")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetContainerNetworkMode indicates an expected call of GetContainerNetworkMode.
+func (mr *MockContainerMetadataGetterMockRecorder) GetContainerNetworkMode() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerNetworkMode", reflect.TypeOf((*MockContainerMetadataGetter)(nil).GetContainerNetworkMode))
+}
+
 // GetContainerRuntimeID mocks base method.
 func (m *MockContainerMetadataGetter) GetContainerRuntimeID() string {
 	m.ctrl.T.Helper()
@@ -104,6 +217,34 @@ func (mr *MockContainerMetadataGetterMockRecorder) GetContainerSentStatusString(
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerSentStatusString", reflect.TypeOf((*MockContainerMetadataGetter)(nil).GetContainerSentStatusString))
 }
 
+// GetContainerStartedAt mocks base method.
+func (m *MockContainerMetadataGetter) GetContainerStartedAt() time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContainerStartedAt")
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// GetContainerStartedAt indicates an expected call of GetContainerStartedAt.
+func (mr *MockContainerMetadataGetterMockRecorder) GetContainerStartedAt() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerStartedAt", reflect.TypeOf((*MockContainerMetadataGetter)(nil).GetContainerStartedAt))
+}
+
+// GetContainerStoppedAt mocks base method.
+func (m *MockContainerMetadataGetter) GetContainerStoppedAt() time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContainerStoppedAt")
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// GetContainerStoppedAt indicates an expected call of GetContainerStoppedAt.
+func (mr *MockContainerMetadataGetterMockRecorder) GetContainerStoppedAt() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerStoppedAt", reflect.TypeOf((*MockContainerMetadataGetter)(nil).GetContainerStoppedAt))
+}
+
 // MockTaskMetadataGetter is a mock of TaskMetadataGetter interface.
 type MockTaskMetadataGetter struct {
 	ctrl     *gomock.Controller
@@ -127,6 +268,20 @@ func (m *MockTaskMetadataGetter) EXPECT() *MockTaskMetadataGetterMockRecorder {
 	return m.recorder
 }
 
+// GetTaskArn mocks base method.
+func (m *MockTaskMetadataGetter) GetTaskArn() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskArn")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetTaskArn indicates an expected call of GetTaskArn.
+func (mr *MockTaskMetadataGetterMockRecorder) GetTaskArn() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskArn", reflect.TypeOf((*MockTaskMetadataGetter)(nil).GetTaskArn))
+}
+
 // GetTaskExecutionStoppedAt mocks base method.
 func (m *MockTaskMetadataGetter) GetTaskExecutionStoppedAt() time.Time {
 	m.ctrl.T.Helper()
@@ -155,6 +310,20 @@ func (mr *MockTaskMetadataGetterMockRecorder) GetTaskIsNil() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskIsNil", reflect.TypeOf((*MockTaskMetadataGetter)(nil).GetTaskIsNil))
 }
 
+// GetTaskKnownStatus mocks base method.
+func (m *MockTaskMetadataGetter) GetTaskKnownStatus() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskKnownStatus")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetTaskKnownStatus indicates an expected call of GetTaskKnownStatus.
+func (mr *MockTaskMetadataGetterMockRecorder) GetTaskKnownStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskKnownStatus", reflect.TypeOf((*MockTaskMetadataGetter)(nil).GetTaskKnownStatus))
+}
+
 // GetTaskPullStartedAt mocks base method.
 func (m *MockTaskMetadataGetter) GetTaskPullStartedAt() time.Time {
 	m.ctrl.T.Helper()
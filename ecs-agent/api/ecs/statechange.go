@@ -14,28 +14,61 @@
 package ecs
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment/resource"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/model/ecs"
+	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/ttime"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/google/uuid"
 )
 
+// _time provides the current time to the state change methods which depend on it, such
+// as the rendering of the time left before an attachment expires.
+var _time ttime.Time = &ttime.DefaultTime{}
+
 // ContainerMetadataGetter retrieves specific information about a given container that ECS client is concerned with.
 type ContainerMetadataGetter interface {
 	GetContainerIsNil() bool
 	GetContainerSentStatusString() string
 	GetContainerRuntimeID() string
 	GetContainerIsEssential() bool
+	GetContainerName() string
+	GetContainerImageDigest() string
+	GetContainerImageName() string
+	GetContainerExitCode() *int
+	GetContainerHealthStatus() apicontainerstatus.ContainerHealthStatus
+	GetContainerNetworkMode() string
+	GetContainerCreatedAt() time.Time
+	GetContainerStartedAt() time.Time
+	GetContainerStoppedAt() time.Time
+	GetContainerAssignedDevices() []string
 }
 
 // TaskMetadataGetter retrieves specific information about a given task that ECS client is concerned with.
 type TaskMetadataGetter interface {
 	GetTaskIsNil() bool
+	GetTaskArn() string
+	GetTaskKnownStatus() string
 	GetTaskSentStatusString() string
 	GetTaskPullStartedAt() time.Time
 	GetTaskPullStoppedAt() time.Time
@@ -47,6 +80,9 @@ type TaskMetadataGetter interface {
 type ContainerStateChange struct {
 	// TaskArn is the unique identifier for the task.
 	TaskArn string
+	// EventID is a client generated UUID identifying the state change. It correlates
+	// the agent's log lines about the change with the submission to the backend.
+	EventID string
 	// RuntimeID is the dockerID of the container.
 	RuntimeID string
 	// ContainerName is the name of the container.
@@ -58,16 +94,224 @@ type ContainerStateChange struct {
 	ImageDigest string
 	// Reason may contain details of why the container stopped.
 	Reason string
+	// ReasonCode is a short stable token, such as CannotPullContainerError or
+	// OutOfMemoryError, classifying the reason. It's set alongside Reason when the
+	// reason was derived from a known error and is empty otherwise.
+	ReasonCode string
 	// ExitCode is the exit code of the container, if available.
 	ExitCode *int
+	// CreatedAt, StartedAt and StoppedAt are the times the container was created,
+	// started and stopped at. Each is nil until the container reached the matching
+	// stage, e.g. StartedAt stays nil for a container that failed to start.
+	CreatedAt *time.Time
+	StartedAt *time.Time
+	StoppedAt *time.Time
+	// AssignedDevices are the IDs of the devices, such as GPUs, assigned to the container.
+	// It is empty when no device is assigned.
+	AssignedDevices []string
 	// NetworkBindings contains the details of the host ports picked for the specified
 	// container ports.
 	NetworkBindings []*ecs.NetworkBinding
+	// ImageCreatedAt is the creation timestamp from the config of the image the
+	// container ran, as observed at the RUNNING transition. It is nil when the
+	// timestamp is unavailable.
+	ImageCreatedAt *time.Time
+	// SELinuxLabels are the SELinux label options resolved from the container's
+	// security options at the RUNNING transition. It is empty for containers that
+	// use default labeling.
+	SELinuxLabels []string
+	// CPUThrottling is a snapshot of the container's CFS throttling stats taken at
+	// the STOPPED transition. It is nil when the stats are unavailable.
+	CPUThrottling *CPUThrottlingStats
+	// StopMethod describes how the container stopped. It is only set for STOPPED
+	// changes where the stop could be observed.
+	StopMethod StopMethod
+	// InitPID is the host PID of the container's init process as observed at the
+	// RUNNING transition. It is zero on platforms where it's not meaningful and
+	// once the container has stopped.
+	InitPID int
+	// RegistryVisibility indicates whether the container's image was pulled from a
+	// public or a private registry, as determined from the registry host of the
+	// image reference.
+	RegistryVisibility string
+	// BridgeNetworkName is the name of the bridge network the container is attached
+	// to, as resolved at the RUNNING transition. Containers on the default bridge
+	// report "bridge", host and awsvpc containers report an empty string.
+	BridgeNetworkName string
+	// AgentInjected indicates that the container was injected into the task by the
+	// agent, such as the Service Connect proxy or a pause container, rather than
+	// defined by the user.
+	AgentInjected bool
+	// ImagePullRateLimited indicates that pulling the container's image failed
+	// because the registry's pull rate limit was reached.
+	ImagePullRateLimited bool
+	// CommandArgCount is the number of arguments of the container's resolved
+	// command line. The command line itself isn't reported.
+	CommandArgCount int
+	// CommandBytes is the size in bytes of the container's resolved command line.
+	CommandBytes int
+	// LocalHostnames are the hostnames the container can be resolved by within the
+	// task, as configured by the network setup. It is empty for default setups.
+	LocalHostnames []string
+	// WorkingSetBytes is the container's working set (resident memory) taken from
+	// the cgroup memory stats at the STOPPED transition. Zero means the stat was
+	// unavailable.
+	WorkingSetBytes int64
+	// DigestMatchedPinned indicates whether the resolved ImageDigest matches the
+	// digest the task definition pinned the image to. It is nil when the image was
+	// referenced by tag.
+	DigestMatchedPinned *bool
+	// DependencyUnmet is the ordering dependency that blocked the container from
+	// ever starting. It is nil when the container's dependencies were satisfied.
+	DependencyUnmet *UnmetDependency
+	// RestartReasons are the exit reasons of the container's most recent restarts
+	// under its restart policy, most recent first. The history is bounded to keep
+	// the payload small.
+	RestartReasons []string
+	// WebIdentityConfigured indicates that web identity credentials were injected
+	// into the container, and WebIdentityTokenPath is the path of the injected
+	// token file. The token itself is never reported.
+	WebIdentityConfigured bool
+	WebIdentityTokenPath  string
+	// AnonymousMemoryBytes and PageCacheBytes split the container's memory usage
+	// into anonymous memory and reclaimable page cache, taken from the cgroup
+	// memory stats at the STOPPED transition. Zero means the stat was unavailable.
+	AnonymousMemoryBytes int64
+	PageCacheBytes       int64
+	// ContainerTags are the container level tags carried by the container's
+	// tag-bearing docker labels. It is empty when no container tags are present.
+	ContainerTags map[string]string
+	// HealthCheckNeverRan indicates that the container stopped before its health
+	// check start period elapsed, so its UNKNOWN health doesn't mean the health
+	// check failed.
+	HealthCheckNeverRan bool
+	// LogMode is the delivery mode, blocking or non-blocking, of the container's
+	// resolved log configuration, and LogBufferSize is the size in bytes of the
+	// buffer used in non-blocking mode. They are set at the RUNNING transition.
+	LogMode       string
+	LogBufferSize int
+	// HealthStatus is the docker health check status of the container at the time
+	// of the state change. It is unknown for containers without a health check.
+	HealthStatus apicontainerstatus.ContainerHealthStatus
+	// HealthReason is the output of the last health check when the container is
+	// unhealthy.
+	HealthReason string
+	// Reconciled indicates that the state change was generated while reconciling
+	// state after an agent restart rather than by a live transition.
+	Reconciled bool
 	// MetadataGetter is used to retrieve other relevant information about the
 	// container.
 	MetadataGetter ContainerMetadataGetter
 }
 
+const (
+	// RegistryVisibilityPublic indicates an image from a public registry.
+	RegistryVisibilityPublic = "Public"
+	// RegistryVisibilityPrivate indicates an image from a private registry.
+	RegistryVisibilityPrivate = "Private"
+)
+
+// StopMethod describes how a container stopped.
+type StopMethod int32
+
+const (
+	// StopMethodUnknown is the zero value, used when the stop wasn't observed.
+	StopMethodUnknown StopMethod = iota
+	// StopMethodSelfExited means the container exited without a stop being requested.
+	StopMethodSelfExited
+	// StopMethodSIGTERM means the container exited after receiving SIGTERM.
+	StopMethodSIGTERM
+	// StopMethodSIGKILL means the container was killed with SIGKILL after the stop
+	// timeout elapsed.
+	StopMethodSIGKILL
+)
+
+// sigkillExitCode is the exit code of a process killed by SIGKILL (128 + 9).
+const sigkillExitCode = 137
+
+const (
+	// DefaultMaxReasonLength is the default maximum length, in bytes, of the reason of a
+	// state change.
+	DefaultMaxReasonLength = 1024
+	// ReasonTruncatedMarker is appended to the reasons truncated by TruncateReason.
+	ReasonTruncatedMarker = "...[truncated]"
+	// ReasonSeparator separates the reasons accumulated by AppendReason.
+	ReasonSeparator = "; "
+	// MaxContainerReasonLength is the maximum length, in bytes, of the reason of a
+	// container state change accepted by ECS.
+	MaxContainerReasonLength = 255
+	// MaxRuntimeIDLength is the maximum length, in bytes, of the runtime ID of a
+	// container accepted by ECS.
+	MaxRuntimeIDLength = 255
+	// MaxImageDigestLength is the maximum length, in bytes, of the image digest of a
+	// container accepted by ECS.
+	MaxImageDigestLength = 255
+)
+
+// defaultBindIPv4 is the wildcard address network bindings use when no bind IP is
+// configured for the port mapping.
+const defaultBindIPv4 = "0.0.0.0"
+
+var stopMethodNames = map[StopMethod]string{
+	StopMethodUnknown:    "Unknown",
+	StopMethodSelfExited: "SelfExited",
+	StopMethodSIGTERM:    "SIGTERM",
+	StopMethodSIGKILL:    "SIGKILL",
+}
+
+// String returns a human readable string representation of a StopMethod.
+func (m StopMethod) String() string {
+	if name, ok := stopMethodNames[m]; ok {
+		return name
+	}
+	return stopMethodNames[StopMethodUnknown]
+}
+
+// NewStopMethod derives the StopMethod from a stop observation: whether a stop was
+// requested for the container and the exit code it stopped with.
+func NewStopMethod(stopRequested bool, exitCode *int) StopMethod {
+	if exitCode == nil {
+		return StopMethodUnknown
+	}
+	if !stopRequested {
+		return StopMethodSelfExited
+	}
+	if *exitCode == sigkillExitCode {
+		return StopMethodSIGKILL
+	}
+	return StopMethodSIGTERM
+}
+
+// CPUThrottlingStats contains the CFS throttling stats of a container.
+type CPUThrottlingStats struct {
+	// ThrottledPeriods is the number of periods in which the container was throttled.
+	ThrottledPeriods uint64
+	// ThrottledTime is the total time the container was throttled for.
+	ThrottledTime time.Duration
+}
+
+// String returns a human readable string representation of CPUThrottlingStats.
+func (s *CPUThrottlingStats) String() string {
+	return fmt.Sprintf("throttledPeriods=%d throttledTime=%s", s.ThrottledPeriods, s.ThrottledTime)
+}
+
+// UnmetDependency is a container ordering dependency that was never satisfied,
+// which prevented the container from starting.
+type UnmetDependency struct {
+	// ContainerName is the name of the container depended on.
+	ContainerName string
+	// Condition is the dependency condition that wasn't met, such as HEALTHY or
+	// SUCCESS.
+	Condition string
+	// Reason describes why the condition could not be met.
+	Reason string
+}
+
+// String returns a human readable string representation of an UnmetDependency.
+func (d *UnmetDependency) String() string {
+	return fmt.Sprintf("containerName=%s condition=%s reason=%s", d.ContainerName, d.Condition, d.Reason)
+}
+
 // TaskStateChange represents a state change that needs to be sent to the
 // SubmitTaskStateChange API.
 type TaskStateChange struct {
@@ -77,10 +321,16 @@ type TaskStateChange struct {
 	ClusterARN string
 	// TaskArn is the unique identifier for the task.
 	TaskARN string
+	// EventID is a client generated UUID identifying the state change. It correlates
+	// the agent's log lines about the change with the submission to the backend.
+	EventID string
 	// Status is the status to send.
 	Status apitaskstatus.TaskStatus
 	// Reason may contain details of why the task stopped.
 	Reason string
+	// ReasonCode is a short stable token classifying the reason, such as the name of
+	// the error that stopped the task's essential container. It is empty when unknown.
+	ReasonCode string
 	// Containers holds the events generated by containers owned by this task.
 	Containers []*ecs.ContainerStateChange
 	// ManagedAgents contain the name and status of Agents running inside the
@@ -92,18 +342,615 @@ type TaskStateChange struct {
 	PullStoppedAt *time.Time
 	// ExecutionStoppedAt is the timestamp when the essential container stopped.
 	ExecutionStoppedAt *time.Time
+	// NetworkConfiguration is a summary of the task's awsvpc network configuration.
+	// It is nil for tasks that don't use awsvpc network mode.
+	NetworkConfiguration *TaskNetworkConfiguration
+	// RuntimePlatformVersion is the version of the runtime platform the task ran
+	// on, as set from the agent's runtime context. It is omitted when unset.
+	RuntimePlatformVersion string
+	// LaunchLatency is the breakdown of the task's launch time into phases. It is
+	// only set for RUNNING changes.
+	LaunchLatency *TaskLaunchLatency
+	// AgentResourcePressure is a snapshot of the agent's own resource usage taken
+	// when a STOPPED change is submitted.
+	AgentResourcePressure *AgentResourcePressure
+	// PauseContainerStatus is the known status of the pause container holding the
+	// network namespace of an awsvpc task. It is nil for other tasks.
+	PauseContainerStatus *apicontainerstatus.ContainerStatus
+	// SubmissionDelayedByThrottling indicates that submitting the change was
+	// delayed because the backend throttled an earlier attempt.
+	SubmissionDelayedByThrottling bool
+	// QueueDelay is the time the change spent queued in the agent before this
+	// submission attempt.
+	QueueDelay time.Duration
+	// Reconciled indicates that the state change was generated while reconciling
+	// state after an agent restart rather than by a live transition.
+	Reconciled bool
 	// MetadataGetter is used to retrieve other relevant information about the task.
 	MetadataGetter TaskMetadataGetter
 }
 
+// TaskNetworkConfiguration summarizes the network configuration of an awsvpc task
+// as derived from its ENI attachment.
+type TaskNetworkConfiguration struct {
+	// ENIID is the id of the task's primary ENI.
+	ENIID string
+	// MACAddress is the mac address of the task's primary ENI.
+	MACAddress string
+	// PrivateIPv4Address is the primary private IPv4 address of the ENI.
+	PrivateIPv4Address string
+	// PrivateIPv6Address is the first IPv6 address of the ENI, if any.
+	PrivateIPv6Address string
+}
+
+// NewTaskNetworkConfiguration builds a TaskNetworkConfiguration from the task's
+// primary network interface. It returns nil if the interface is nil.
+func NewTaskNetworkConfiguration(eni *ni.NetworkInterface) *TaskNetworkConfiguration {
+	if eni == nil {
+		return nil
+	}
+	config := &TaskNetworkConfiguration{
+		ENIID:              eni.ID,
+		MACAddress:         eni.MacAddress,
+		PrivateIPv4Address: eni.GetPrimaryIPv4Address(),
+	}
+	if ipv6Addresses := eni.GetIPV6Addresses(); len(ipv6Addresses) > 0 {
+		config.PrivateIPv6Address = ipv6Addresses[0]
+	}
+	return config
+}
+
+// String returns a human readable string representation of a TaskNetworkConfiguration.
+func (n *TaskNetworkConfiguration) String() string {
+	return fmt.Sprintf("eniID=%s macAddress=%s privateIPv4Address=%s privateIPv6Address=%s",
+		n.ENIID, n.MACAddress, n.PrivateIPv4Address, n.PrivateIPv6Address)
+}
+
+// TaskLaunchLatency is the breakdown of a task's launch time into phases. Phases
+// that didn't occur or couldn't be measured report zero.
+type TaskLaunchLatency struct {
+	// Pull is the time spent pulling the task's images.
+	Pull time.Duration
+	// Create is the time from the pull finishing to the last container being created.
+	Create time.Duration
+	// Start is the time from the last container being created to the last container
+	// being started.
+	Start time.Duration
+}
+
+// NewTaskLaunchLatency computes a TaskLaunchLatency from the transition timestamps of a
+// task. Zero timestamps are treated as transitions that didn't occur.
+func NewTaskLaunchLatency(pullStartedAt, pullStoppedAt, createdAt, startedAt time.Time) *TaskLaunchLatency {
+	return &TaskLaunchLatency{
+		Pull:   durationBetween(pullStartedAt, pullStoppedAt),
+		Create: durationBetween(pullStoppedAt, createdAt),
+		Start:  durationBetween(createdAt, startedAt),
+	}
+}
+
+// String returns a human readable string representation of a TaskLaunchLatency.
+func (l *TaskLaunchLatency) String() string {
+	return fmt.Sprintf("pull=%s create=%s start=%s", l.Pull, l.Create, l.Start)
+}
+
+// durationBetween returns the duration between two timestamps, or zero if either of
+// them is unset or they are out of order.
+func durationBetween(from, to time.Time) time.Duration {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return 0
+	}
+	return to.Sub(from)
+}
+
+// AgentResourcePressure is a snapshot of the agent's own resource usage.
+type AgentResourcePressure struct {
+	// Goroutines is the number of goroutines of the agent.
+	Goroutines int
+	// HeapBytes is the number of bytes of allocated heap objects of the agent.
+	HeapBytes uint64
+	// EventQueueDepth is the number of state changes queued for submission.
+	EventQueueDepth int
+}
+
+// NewAgentResourcePressure takes a snapshot of the agent's resource usage along with
+// the given depth of the state change queue.
+func NewAgentResourcePressure(eventQueueDepth int) *AgentResourcePressure {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	return &AgentResourcePressure{
+		Goroutines:      runtime.NumGoroutine(),
+		HeapBytes:       memStats.HeapAlloc,
+		EventQueueDepth: eventQueueDepth,
+	}
+}
+
+// String returns a human readable string representation of an AgentResourcePressure.
+func (p *AgentResourcePressure) String() string {
+	return fmt.Sprintf("goroutines=%d heapBytes=%d eventQueueDepth=%d",
+		p.Goroutines, p.HeapBytes, p.EventQueueDepth)
+}
+
 // AttachmentStateChange represents a state change that needs to be sent to the
 // SubmitAttachmentStateChanges API.
 type AttachmentStateChange struct {
 	// Attachment is the attachment object to send.
 	Attachment attachment.Attachment
+	// EventID is a client generated UUID identifying the state change. It correlates
+	// the agent's log lines about the change with the submission to the backend.
+	EventID string
+}
+
+// VolumeAttachmentStateChange represents a state change of a volume attachment, such as an
+// EBS volume attached to a task, that needs to be sent to the SubmitAttachmentStateChanges
+// API. Unlike AttachmentStateChange, it only carries what is reported for the volume.
+type VolumeAttachmentStateChange struct {
+	// AttachmentARN is the ARN of the volume attachment.
+	AttachmentARN string
+	// VolumeID is the ID of the attached volume.
+	VolumeID string
+	// Status is the attachment status to send.
+	Status attachment.AttachmentStatus
+	// EventID is a client generated UUID identifying the state change. It correlates
+	// the agent's log lines about the change with the submission to the backend.
+	EventID string
+}
+
+// NewVolumeAttachmentStateChange creates a VolumeAttachmentStateChange reporting the
+// current status of the given resource attachment.
+func NewVolumeAttachmentStateChange(ra *resource.ResourceAttachment) *VolumeAttachmentStateChange {
+	return &VolumeAttachmentStateChange{
+		AttachmentARN: ra.GetAttachmentARN(),
+		VolumeID:      ra.GetAttachmentProperties(resource.VolumeIdKey),
+		Status:        ra.GetAttachmentStatus(),
+		EventID:       NewEventID(),
+	}
+}
+
+// TaskARNPrefixFilter returns a predicate that accepts the container and task
+// state changes whose task ARN starts with the given prefix. The predicate can be
+// used to filter the events delivered to a subscriber.
+func TaskARNPrefixFilter(prefix string) func(event interface{}) bool {
+	return func(event interface{}) bool {
+		switch change := event.(type) {
+		case *ContainerStateChange:
+			return strings.HasPrefix(change.TaskArn, prefix)
+		case ContainerStateChange:
+			return strings.HasPrefix(change.TaskArn, prefix)
+		case *TaskStateChange:
+			return strings.HasPrefix(change.TaskARN, prefix)
+		case TaskStateChange:
+			return strings.HasPrefix(change.TaskARN, prefix)
+		default:
+			return false
+		}
+	}
+}
+
+// imageDigestRegex matches a sha256 image digest.
+var imageDigestRegex = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// redactedValue replaces the sensitive data masked by RedactSensitiveData.
+const redactedValue = "REDACTED"
+
+// sensitiveDataPatterns are the patterns of the sensitive data masked by
+// RedactSensitiveData, along with their replacement. They are kept narrow so that regular
+// content such as ARNs and image references is left readable.
+var sensitiveDataPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	// AWS access key IDs of long-term and temporary credentials.
+	{regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), redactedValue},
+	// Bearer tokens, e.g. in Authorization headers quoted by registry errors.
+	{regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9\-._~+/]+=*`), "${1}" + redactedValue},
+	// Credentials embedded in query strings, such as the signature of presigned URLs.
+	{regexp.MustCompile(`(?i)([?&](?:x-amz-signature|x-amz-credential|x-amz-security-token|signature|` +
+		`awsaccesskeyid|access_token|token|password|secret)=)[^&\s"']+`), "${1}" + redactedValue},
+}
+
+// RedactSensitiveData masks the data matching known sensitive patterns in s: AWS access key
+// IDs, bearer tokens and credentials embedded in query strings. It's used to keep the
+// secrets leaked by errors, e.g. in the reason of a state change, out of the logs.
+func RedactSensitiveData(s string) string {
+	for _, sensitive := range sensitiveDataPatterns {
+		s = sensitive.pattern.ReplaceAllString(s, sensitive.replacement)
+	}
+	return s
+}
+
+// StateChangeValidationError is returned when a state change contains an
+// inconsistent combination of fields.
+type StateChangeValidationError struct {
+	// Field is the name of the offending field.
+	Field string
+	// Reason describes the violated invariant.
+	Reason string
+}
+
+// Error returns the error message of a StateChangeValidationError.
+func (e *StateChangeValidationError) Error() string {
+	return fmt.Sprintf("invalid state change: %s %s", e.Field, e.Reason)
+}
+
+// ErrStatusRegression is returned by the Submittable methods of the state changes whose
+// status is earlier in the lifecycle than the status already sent. The ECS client returns
+// it when it drops such a change, so that callers don't record the change as submitted.
+var ErrStatusRegression = errors.New("state change status regresses the sent status")
+
+// NewEventID generates the ID of a state change event.
+func NewEventID() string {
+	return uuid.New().String()
+}
+
+// ReasonCodeFromError returns the reason code of a state change caused by err, i.e. the
+// name of the first NamedError in its chain. An empty string is returned if err is nil or
+// doesn't wrap a NamedError.
+func ReasonCodeFromError(err error) string {
+	var namedErr apierrors.NamedError
+	if err == nil || !errors.As(err, &namedErr) {
+		return ""
+	}
+	return namedErr.ErrorName()
+}
+
+// TruncateReason caps the reason of a state change to maxLength bytes, replacing the end of
+// a longer reason with ReasonTruncatedMarker. The reason is never cut in the middle of a
+// UTF-8 encoded character. DefaultMaxReasonLength is used if maxLength isn't positive.
+func TruncateReason(reason string, maxLength int) string {
+	if maxLength <= 0 {
+		maxLength = DefaultMaxReasonLength
+	}
+	if len(reason) <= maxLength {
+		return reason
+	}
+	marker := ReasonTruncatedMarker
+	if maxLength < len(marker) {
+		marker = ""
+	}
+	cut := maxLength - len(marker)
+	for cut > 0 && !utf8.RuneStart(reason[cut]) {
+		cut--
+	}
+	return reason[:cut] + marker
+}
+
+// AppendReason appends next to reason, separated by ReasonSeparator, and truncates the
+// result to DefaultMaxReasonLength. Once the reason has been truncated, the reasons
+// appended to it are dropped, so that the earliest context is preserved. An empty next
+// leaves reason unchanged.
+func AppendReason(reason, next string) string {
+	if next == "" {
+		return reason
+	}
+	if reason == "" {
+		return TruncateReason(next, DefaultMaxReasonLength)
+	}
+	return TruncateReason(reason+ReasonSeparator+next, DefaultMaxReasonLength)
+}
+
+// AppendReason appends reason to the Reason of the ContainerStateChange rather than
+// overwriting it, so that the context of earlier failures is kept, e.g. across retries.
+// The reasons are separated by ReasonSeparator and the result is truncated to
+// DefaultMaxReasonLength. Empty reasons are ignored.
+func (c *ContainerStateChange) AppendReason(reason string) {
+	c.Reason = AppendReason(c.Reason, reason)
+}
+
+// AppendReason appends reason to the Reason of the TaskStateChange rather than
+// overwriting it. It behaves like ContainerStateChange.AppendReason.
+func (change *TaskStateChange) AppendReason(reason string) {
+	change.Reason = AppendReason(change.Reason, reason)
+}
+
+// NewContainerStateChange builds a ContainerStateChange for the given status, populating
+// the fields derived from the task and container through their metadata getters, and
+// generates its event ID. The exit code is only set for terminal statuses.
+func NewContainerStateChange(task TaskMetadataGetter, container ContainerMetadataGetter,
+	status apicontainerstatus.ContainerStatus) (*ContainerStateChange, error) {
+	if task == nil || task.GetTaskIsNil() {
+		return nil, errors.New("create container state change: task is nil")
+	}
+	if container == nil || container.GetContainerIsNil() {
+		return nil, errors.New("create container state change: container is nil")
+	}
+	change := &ContainerStateChange{
+		TaskArn:        task.GetTaskArn(),
+		EventID:        NewEventID(),
+		RuntimeID:      container.GetContainerRuntimeID(),
+		ContainerName:  container.GetContainerName(),
+		Status:         status,
+		ImageDigest:    container.GetContainerImageDigest(),
+		HealthStatus:   container.GetContainerHealthStatus(),
+		CreatedAt:      timePtrOrNil(container.GetContainerCreatedAt()),
+		StartedAt:      timePtrOrNil(container.GetContainerStartedAt()),
+		StoppedAt:      timePtrOrNil(container.GetContainerStoppedAt()),
+		MetadataGetter: container,
+	}
+	if devices := container.GetContainerAssignedDevices(); len(devices) != 0 {
+		change.AssignedDevices = devices
+	}
+	if status.Terminal() {
+		change.ExitCode = container.GetContainerExitCode()
+	}
+	return change, nil
+}
+
+// timePtrOrNil returns a pointer to t, or nil if t is the zero time.
+func timePtrOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// Runtime returns how long the container ran for, i.e. the time between StartedAt and
+// StoppedAt. False is returned if the container never started, hasn't stopped yet, or
+// the timestamps are inconsistent and would yield a negative runtime.
+func (c *ContainerStateChange) Runtime() (time.Duration, bool) {
+	if c.StartedAt == nil || c.StoppedAt == nil || c.StoppedAt.Before(*c.StartedAt) {
+		return 0, false
+	}
+	return c.StoppedAt.Sub(*c.StartedAt), true
+}
+
+// UsesHostNetwork returns true if the container uses the host network mode, as reported
+// by the metadata getter. The network bindings of such containers are redundant with the
+// ports the container listens on, so String renders the network mode in their place. They
+// are still submitted as is, as ECS relies on them, e.g. to register load balancer targets.
+func (c *ContainerStateChange) UsesHostNetwork() bool {
+	return c.MetadataGetter != nil && !c.MetadataGetter.GetContainerIsNil() &&
+		c.MetadataGetter.GetContainerNetworkMode() == ecs.NetworkModeHost
+}
+
+// ResolvedExitCode returns the exit code to report for the container. The explicit
+// ExitCode field takes precedence: it is set by the caller that observed the container
+// stop and is returned as is, even if the container recorded a different value. When the
+// field is nil and the status is terminal, the exit code recorded on the container is
+// read through the metadata getter instead. Nil is returned when neither source has one.
+func (c *ContainerStateChange) ResolvedExitCode() *int {
+	if c.ExitCode != nil {
+		return c.ExitCode
+	}
+	if !c.Status.Terminal() || c.MetadataGetter == nil || c.MetadataGetter.GetContainerIsNil() {
+		return nil
+	}
+	return c.MetadataGetter.GetContainerExitCode()
+}
+
+// Validate checks the invariants of a ContainerStateChange and returns a
+// StateChangeValidationError describing the first violation found.
+func (c *ContainerStateChange) Validate() error {
+	if c.TaskArn == "" {
+		return &StateChangeValidationError{Field: "TaskArn", Reason: "must not be empty"}
+	}
+	if c.ContainerName == "" {
+		return &StateChangeValidationError{Field: "ContainerName", Reason: "must not be empty"}
+	}
+	if c.ExitCode != nil && !c.Status.Terminal() {
+		return &StateChangeValidationError{Field: "ExitCode",
+			Reason: fmt.Sprintf("must not be set for a container in status %s", c.Status.String())}
+	}
+	if c.Status.Terminal() && len(c.NetworkBindings) != 0 && c.RuntimeID == "" {
+		return &StateChangeValidationError{Field: "RuntimeID",
+			Reason: "must not be empty for a stopped container with network bindings"}
+	}
+	if c.ImageDigest != "" && !imageDigestRegex.MatchString(c.ImageDigest) {
+		return &StateChangeValidationError{Field: "ImageDigest",
+			Reason: fmt.Sprintf("is not a sha256 digest: %s", c.ImageDigest)}
+	}
+	return nil
+}
+
+// IsStatusRegression returns true if the status of the change is earlier in the container
+// lifecycle than the status already sent for the container, as reported by the metadata
+// getter. A change whose container has no known sent status is not a regression.
+func (c *ContainerStateChange) IsStatusRegression() bool {
+	if c.MetadataGetter == nil || c.MetadataGetter.GetContainerIsNil() {
+		return false
+	}
+	sentStatus, ok := parseContainerStatus(c.MetadataGetter.GetContainerSentStatusString())
+	if !ok || sentStatus == apicontainerstatus.ContainerStatusNone {
+		return false
+	}
+	return c.Status < sentStatus
+}
+
+// Submittable is the canonical check of whether the ContainerStateChange should be sent to
+// the backend. It composes all the pre-submission checks: the change must be valid and must
+// not regress the status already sent for the container. It returns false with the reason,
+// either the validation error or ErrStatusRegression, when the change should be dropped.
+func (c *ContainerStateChange) Submittable() (bool, error) {
+	if err := c.Validate(); err != nil {
+		return false, err
+	}
+	if c.IsStatusRegression() {
+		return false, fmt.Errorf("%w: container %s is %s", ErrStatusRegression, c.ContainerName,
+			c.Status.String())
+	}
+	return true, nil
+}
+
+// parseContainerStatus parses the string representation of a container status.
+func parseContainerStatus(s string) (apicontainerstatus.ContainerStatus, bool) {
+	var status apicontainerstatus.ContainerStatus
+	if s == "" {
+		return status, false
+	}
+	encoded, err := json.Marshal(s)
+	if err != nil {
+		return status, false
+	}
+	if err := json.Unmarshal(encoded, &status); err != nil {
+		return status, false
+	}
+	return status, true
+}
+
+// networkBindingsString renders network bindings as a list of
+// containerPort->hostPort/protocol entries. The bind IP is prefixed to the host
+// port unless it is the IPv4 wildcard address, so that the IPv4 and IPv6 bindings
+// of the same port remain distinguishable.
+func networkBindingsString(bindings []*ecs.NetworkBinding) string {
+	return "[" + strings.Join(networkBindingEntries(bindings), " ") + "]"
+}
+
+// networkBindingEntries renders each non-nil network binding as a
+// containerPort->hostPort/protocol entry, preserving their order.
+func networkBindingEntries(bindings []*ecs.NetworkBinding) []string {
+	rendered := make([]string, 0, len(bindings))
+	for _, binding := range bindings {
+		if binding == nil {
+			continue
+		}
+		containerPort := aws.StringValue(binding.ContainerPortRange)
+		if containerPort == "" {
+			containerPort = strconv.FormatInt(aws.Int64Value(binding.ContainerPort), 10)
+		}
+		hostPort := aws.StringValue(binding.HostPortRange)
+		if hostPort == "" {
+			hostPort = strconv.FormatInt(aws.Int64Value(binding.HostPort), 10)
+		}
+		if bindIP := aws.StringValue(binding.BindIP); bindIP != "" && bindIP != defaultBindIPv4 {
+			hostPort = net.JoinHostPort(bindIP, hostPort)
+		}
+		protocol := aws.StringValue(binding.Protocol)
+		if protocol == "" {
+			protocol = ecs.TransportProtocolTcp
+		}
+		rendered = append(rendered, fmt.Sprintf("%s->%s/%s", containerPort, hostPort, protocol))
+	}
+	return rendered
+}
+
+// networkBindingKey identifies a network binding by its ports, protocol and bind IP.
+type networkBindingKey struct {
+	containerPort      int64
+	containerPortRange string
+	hostPort           int64
+	hostPortRange      string
+	protocol           string
+	bindIP             string
+}
+
+// DedupNetworkBindings returns the network bindings with duplicates removed, preserving
+// the order of first occurrence. Bindings are duplicates if they have the same container
+// port, host port, protocol and bind IP, or port ranges for range bindings. Nil bindings
+// are dropped.
+func DedupNetworkBindings(bindings []*ecs.NetworkBinding) []*ecs.NetworkBinding {
+	if bindings == nil {
+		return nil
+	}
+	seen := make(map[networkBindingKey]struct{}, len(bindings))
+	deduped := make([]*ecs.NetworkBinding, 0, len(bindings))
+	for _, binding := range bindings {
+		if binding == nil {
+			continue
+		}
+		key := networkBindingKey{
+			containerPort:      aws.Int64Value(binding.ContainerPort),
+			containerPortRange: aws.StringValue(binding.ContainerPortRange),
+			hostPort:           aws.Int64Value(binding.HostPort),
+			hostPortRange:      aws.StringValue(binding.HostPortRange),
+			protocol:           aws.StringValue(binding.Protocol),
+			bindIP:             aws.StringValue(binding.BindIP),
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		deduped = append(deduped, binding)
+	}
+	return deduped
+}
+
+// networkBindingRangeKey identifies the bindings that may be collapsed into the same range
+// binding.
+type networkBindingRangeKey struct {
+	protocol string
+	bindIP   string
+}
+
+// CollapseNetworkBindingRanges returns the network bindings with every block of two or
+// more single port bindings of the same protocol and bind IP, whose container ports and
+// host ports are both consecutive, collapsed into a single range binding, e.g. the
+// bindings of container ports 8000 to 8010 published on host ports 32000 to 32010 become
+// 8000-8010->32000-32010/tcp. Each range takes the position of the first binding of its
+// block. Other bindings are returned as is and nil bindings are dropped. The bindings are
+// expected to be free of duplicates.
+func CollapseNetworkBindingRanges(bindings []*ecs.NetworkBinding) []*ecs.NetworkBinding {
+	if bindings == nil {
+		return nil
+	}
+	groups := make(map[networkBindingRangeKey][]*ecs.NetworkBinding)
+	positions := make(map[*ecs.NetworkBinding]int, len(bindings))
+	for i, binding := range bindings {
+		if binding == nil || binding.ContainerPort == nil || binding.HostPort == nil {
+			continue
+		}
+		positions[binding] = i
+		key := networkBindingRangeKey{
+			protocol: aws.StringValue(binding.Protocol),
+			bindIP:   aws.StringValue(binding.BindIP),
+		}
+		groups[key] = append(groups[key], binding)
+	}
+
+	// Map the first binding of each block, in the original order, to the binding replacing
+	// the block, and the other bindings of the block to nil.
+	replacements := make(map[*ecs.NetworkBinding]*ecs.NetworkBinding)
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			return aws.Int64Value(group[i].ContainerPort) < aws.Int64Value(group[j].ContainerPort)
+		})
+		for start := 0; start < len(group); {
+			end := start + 1
+			for end < len(group) &&
+				aws.Int64Value(group[end].ContainerPort) == aws.Int64Value(group[end-1].ContainerPort)+1 &&
+				aws.Int64Value(group[end].HostPort) == aws.Int64Value(group[end-1].HostPort)+1 {
+				end++
+			}
+			if end-start > 1 {
+				block := group[start:end]
+				first, last := block[0], block[len(block)-1]
+				rangeBinding := &ecs.NetworkBinding{
+					BindIP: first.BindIP,
+					ContainerPortRange: aws.String(fmt.Sprintf("%d-%d",
+						aws.Int64Value(first.ContainerPort), aws.Int64Value(last.ContainerPort))),
+					HostPortRange: aws.String(fmt.Sprintf("%d-%d",
+						aws.Int64Value(first.HostPort), aws.Int64Value(last.HostPort))),
+					Protocol: first.Protocol,
+				}
+				firstInOrder := first
+				for _, binding := range block {
+					replacements[binding] = nil
+					if positions[binding] < positions[firstInOrder] {
+						firstInOrder = binding
+					}
+				}
+				replacements[firstInOrder] = rangeBinding
+			}
+			start = end
+		}
+	}
+
+	collapsed := make([]*ecs.NetworkBinding, 0, len(bindings))
+	for _, binding := range bindings {
+		if binding == nil {
+			continue
+		}
+		if replacement, ok := replacements[binding]; ok {
+			if replacement != nil {
+				collapsed = append(collapsed, replacement)
+			}
+			continue
+		}
+		collapsed = append(collapsed, binding)
+	}
+	return collapsed
 }
 
 // String returns a human readable string representation of a ContainerStateChange.
+// Sensitive data, such as credentials leaked into the reason, is redacted.
 func (c *ContainerStateChange) String() string {
 	res := fmt.Sprintf("containerName=%s containerStatus=%s", c.ContainerName, c.Status.String())
 	if c.ExitCode != nil {
@@ -112,33 +959,352 @@ func (c *ContainerStateChange) String() string {
 	if c.Reason != "" {
 		res += " containerReason=" + c.Reason
 	}
-	if len(c.NetworkBindings) != 0 {
-		res += fmt.Sprintf(" containerNetworkBindings=%v", c.NetworkBindings)
+	if c.ReasonCode != "" {
+		res += " reasonCode=" + c.ReasonCode
+	}
+	if c.MetadataGetter != nil && !c.MetadataGetter.GetContainerIsNil() {
+		if imageName := c.MetadataGetter.GetContainerImageName(); imageName != "" {
+			res += " containerImage=" + imageName
+		}
+	}
+	if c.ImageDigest != "" {
+		res += " containerImageDigest=" + c.ImageDigest
+	}
+	if c.CreatedAt != nil {
+		res += " containerCreatedAt=" + c.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	if c.StartedAt != nil {
+		res += " containerStartedAt=" + c.StartedAt.UTC().Format(time.RFC3339)
+	}
+	if c.StoppedAt != nil {
+		res += " containerStoppedAt=" + c.StoppedAt.UTC().Format(time.RFC3339)
+	}
+	if len(c.AssignedDevices) != 0 {
+		res += fmt.Sprintf(" containerAssignedDevices=%v", c.AssignedDevices)
+	}
+	if c.UsesHostNetwork() {
+		res += " networkMode=" + ecs.NetworkModeHost
+	} else if len(c.NetworkBindings) != 0 {
+		res += " containerNetworkBindings=" + networkBindingsString(c.NetworkBindings)
+	}
+	if c.ImageCreatedAt != nil {
+		res += " containerImageCreatedAt=" + c.ImageCreatedAt.UTC().Format(time.RFC3339)
+	}
+	if len(c.SELinuxLabels) != 0 {
+		res += fmt.Sprintf(" containerSELinuxLabels=%v", c.SELinuxLabels)
+	}
+	if c.CPUThrottling != nil {
+		res += fmt.Sprintf(" containerCPUThrottling={%s}", c.CPUThrottling.String())
+	}
+	if c.StopMethod != StopMethodUnknown {
+		res += " containerStopMethod=" + c.StopMethod.String()
+	}
+	if c.InitPID != 0 {
+		res += " containerInitPID=" + strconv.Itoa(c.InitPID)
+	}
+	if c.RegistryVisibility != "" {
+		res += " containerRegistryVisibility=" + c.RegistryVisibility
+	}
+	if c.BridgeNetworkName != "" {
+		res += " containerBridgeNetworkName=" + c.BridgeNetworkName
+	}
+	if c.AgentInjected {
+		res += " containerAgentInjected=true"
+	}
+	if c.ImagePullRateLimited {
+		res += " containerImagePullRateLimited=true"
+	}
+	if c.CommandArgCount != 0 {
+		res += fmt.Sprintf(" containerCommandArgCount=%d containerCommandBytes=%d", c.CommandArgCount, c.CommandBytes)
+	}
+	if len(c.LocalHostnames) != 0 {
+		res += fmt.Sprintf(" containerLocalHostnames=%v", c.LocalHostnames)
+	}
+	if c.WorkingSetBytes != 0 {
+		res += " containerWorkingSetBytes=" + strconv.FormatInt(c.WorkingSetBytes, 10)
+	}
+	if c.DigestMatchedPinned != nil {
+		res += " containerDigestMatchedPinned=" + strconv.FormatBool(*c.DigestMatchedPinned)
+	}
+	if c.DependencyUnmet != nil {
+		res += fmt.Sprintf(" containerDependencyUnmet={%s}", c.DependencyUnmet.String())
+	}
+	if len(c.RestartReasons) != 0 {
+		res += fmt.Sprintf(" containerRestartReasons=%q", c.RestartReasons)
+	}
+	if c.WebIdentityConfigured {
+		res += " containerWebIdentityConfigured=true containerWebIdentityTokenPath=" + c.WebIdentityTokenPath
+	}
+	if c.AnonymousMemoryBytes != 0 || c.PageCacheBytes != 0 {
+		res += fmt.Sprintf(" containerAnonymousMemoryBytes=%d containerPageCacheBytes=%d",
+			c.AnonymousMemoryBytes, c.PageCacheBytes)
+	}
+	if len(c.ContainerTags) != 0 {
+		res += fmt.Sprintf(" containerTags=%v", c.ContainerTags)
+	}
+	if c.HealthCheckNeverRan {
+		res += " containerHealthCheckNeverRan=true"
+	}
+	if c.LogMode != "" {
+		res += " containerLogMode=" + c.LogMode
+	}
+	if c.LogBufferSize != 0 {
+		res += " containerLogBufferSize=" + strconv.Itoa(c.LogBufferSize)
+	}
+	if c.HealthStatus != apicontainerstatus.ContainerHealthUnknown {
+		res += " containerHealthStatus=" + c.HealthStatus.BackendStatus()
+	}
+	if c.HealthReason != "" {
+		res += " containerHealthReason=" + c.HealthReason
+	}
+	if c.EventID != "" {
+		res += " containerEventID=" + c.EventID
+	}
+	if c.Reconciled {
+		res += " containerReconciled=true"
 	}
 	if c.MetadataGetter != nil && !c.MetadataGetter.GetContainerIsNil() {
 		res += fmt.Sprintf(" containerKnownSentStatus=%s containerRuntimeID=%s containerIsEssential=%v",
 			c.MetadataGetter.GetContainerSentStatusString(), c.MetadataGetter.GetContainerRuntimeID(),
 			c.MetadataGetter.GetContainerIsEssential())
 	}
-	return res
+	return RedactSensitiveData(res)
+}
+
+// BatchContainerStateChanges folds the state changes of containers of a single task into
+// the Containers of a TaskStateChange, so that they can be submitted together. Only one
+// change is kept per container: the one with the furthest status, or the last one of
+// those when several have the same status. Containers are listed in the order in which
+// they first appear. An error is returned if the changes belong to more than one task.
+func BatchContainerStateChanges(changes []*ContainerStateChange) (*TaskStateChange, error) {
+	var taskARN string
+	var containerNames []string
+	latest := make(map[string]*ContainerStateChange)
+	for _, change := range changes {
+		if change == nil {
+			continue
+		}
+		if taskARN == "" {
+			taskARN = change.TaskArn
+		} else if change.TaskArn != taskARN {
+			return nil, fmt.Errorf("batch container state changes: changes span multiple tasks: %s and %s",
+				taskARN, change.TaskArn)
+		}
+		current, ok := latest[change.ContainerName]
+		if !ok {
+			containerNames = append(containerNames, change.ContainerName)
+		}
+		if !ok || change.Status >= current.Status {
+			latest[change.ContainerName] = change
+		}
+	}
+	if taskARN == "" {
+		return nil, errors.New("batch container state changes: no container state changes for a task")
+	}
+
+	taskChange := &TaskStateChange{TaskARN: taskARN, EventID: NewEventID()}
+	for _, name := range containerNames {
+		taskChange.Containers = append(taskChange.Containers, latest[name].ToWire())
+	}
+	return taskChange, nil
+}
+
+// managedAgentKey identifies a managed agent of a task.
+type managedAgentKey struct {
+	containerName    string
+	managedAgentName string
+}
+
+// DedupManagedAgentStateChanges returns the managed agent state changes with a single
+// change kept per managed agent, i.e. per container name and managed agent name. Changes
+// are expected in the order they happened, so the last change of a managed agent is the
+// one kept, at the position of its first change. Agents of the same name running in
+// different containers are distinct and are never merged. Nil changes are dropped.
+func DedupManagedAgentStateChanges(changes []*ecs.ManagedAgentStateChange) []*ecs.ManagedAgentStateChange {
+	if changes == nil {
+		return nil
+	}
+	positions := make(map[managedAgentKey]int, len(changes))
+	deduped := make([]*ecs.ManagedAgentStateChange, 0, len(changes))
+	for _, change := range changes {
+		if change == nil {
+			continue
+		}
+		key := managedAgentKey{
+			containerName:    aws.StringValue(change.ContainerName),
+			managedAgentName: aws.StringValue(change.ManagedAgentName),
+		}
+		if i, ok := positions[key]; ok {
+			deduped[i] = change
+			continue
+		}
+		positions[key] = len(deduped)
+		deduped = append(deduped, change)
+	}
+	return deduped
+}
+
+// ToWire converts the ContainerStateChange to the container state change model of the
+// ECS API. It's the mapping used to submit container state changes: the runtime ID, image
+// digest and reason are trimmed to the lengths accepted by ECS, a DEAD status is reported
+// as STOPPED and statuses other than RUNNING and STOPPED as PENDING, and the exit code is
+// the one returned by ResolvedExitCode. Optional fields are only set when they have a
+// value. The container timestamps and assigned devices aren't part of that model and are
+// only reported in the agent's logs and persisted state.
+func (c *ContainerStateChange) ToWire() *ecs.ContainerStateChange {
+	status := c.Status
+	if status.String() == "DEAD" {
+		status = apicontainerstatus.ContainerStopped
+	}
+	wire := &ecs.ContainerStateChange{
+		ContainerName:   aws.String(c.ContainerName),
+		Status:          aws.String(status.BackendStatusString()),
+		NetworkBindings: c.NetworkBindings,
+	}
+	if c.RuntimeID != "" {
+		wire.RuntimeId = aws.String(trimString(c.RuntimeID, MaxRuntimeIDLength))
+	}
+	if c.ImageDigest != "" {
+		wire.ImageDigest = aws.String(trimString(c.ImageDigest, MaxImageDigestLength))
+	}
+	if c.Reason != "" {
+		wire.Reason = aws.String(trimString(c.Reason, MaxContainerReasonLength))
+	}
+	if exitCode := c.ResolvedExitCode(); exitCode != nil {
+		wire.ExitCode = aws.Int64(int64(aws.IntValue(exitCode)))
+	}
+	return wire
+}
+
+// trimString trims s to at most maxLen bytes.
+func trimString(s string, maxLen int) string {
+	if len(s) > maxLen {
+		return s[:maxLen]
+	}
+	return s
+}
+
+// containerStateChangeJSON is the representation of a ContainerStateChange persisted to
+// disk. It only holds the data fields of the state change; the metadata getter refers to
+// live agent state and can't be persisted.
+type containerStateChangeJSON struct {
+	TaskArn         string                             `json:"taskArn,omitempty"`
+	EventID         string                             `json:"eventId,omitempty"`
+	RuntimeID       string                             `json:"runtimeId,omitempty"`
+	ContainerName   string                             `json:"containerName,omitempty"`
+	Status          apicontainerstatus.ContainerStatus `json:"status"`
+	ImageDigest     string                             `json:"imageDigest,omitempty"`
+	Reason          string                             `json:"reason,omitempty"`
+	ReasonCode      string                             `json:"reasonCode,omitempty"`
+	ExitCode        *int                               `json:"exitCode,omitempty"`
+	NetworkBindings []*ecs.NetworkBinding              `json:"networkBindings,omitempty"`
+	CreatedAt       *time.Time                         `json:"createdAt,omitempty"`
+	StartedAt       *time.Time                         `json:"startedAt,omitempty"`
+	StoppedAt       *time.Time                         `json:"stoppedAt,omitempty"`
+	AssignedDevices []string                           `json:"assignedDevices,omitempty"`
+}
+
+// MarshalJSON encodes the data fields of a ContainerStateChange, omitting the metadata
+// getter.
+func (c ContainerStateChange) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&containerStateChangeJSON{
+		TaskArn:         c.TaskArn,
+		EventID:         c.EventID,
+		RuntimeID:       c.RuntimeID,
+		ContainerName:   c.ContainerName,
+		Status:          c.Status,
+		ImageDigest:     c.ImageDigest,
+		Reason:          c.Reason,
+		ReasonCode:      c.ReasonCode,
+		ExitCode:        c.ExitCode,
+		NetworkBindings: c.NetworkBindings,
+		CreatedAt:       c.CreatedAt,
+		StartedAt:       c.StartedAt,
+		StoppedAt:       c.StoppedAt,
+		AssignedDevices: c.AssignedDevices,
+	})
+}
+
+// UnmarshalJSON decodes a ContainerStateChange encoded by MarshalJSON. The metadata
+// getter of the decoded state change is nil.
+func (c *ContainerStateChange) UnmarshalJSON(b []byte) error {
+	var decoded containerStateChangeJSON
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return err
+	}
+	*c = ContainerStateChange{
+		TaskArn:         decoded.TaskArn,
+		EventID:         decoded.EventID,
+		RuntimeID:       decoded.RuntimeID,
+		ContainerName:   decoded.ContainerName,
+		Status:          decoded.Status,
+		ImageDigest:     decoded.ImageDigest,
+		Reason:          decoded.Reason,
+		ReasonCode:      decoded.ReasonCode,
+		ExitCode:        decoded.ExitCode,
+		NetworkBindings: decoded.NetworkBindings,
+		CreatedAt:       decoded.CreatedAt,
+		StartedAt:       decoded.StartedAt,
+		StoppedAt:       decoded.StoppedAt,
+		AssignedDevices: decoded.AssignedDevices,
+	}
+	return nil
 }
 
 // String returns a human readable string representation of a TaskStateChange.
+// Sensitive data, such as credentials leaked into the reason, is redacted.
 func (change *TaskStateChange) String() string {
 	res := fmt.Sprintf("%s -> %s", change.TaskARN, change.Status.String())
 	if len(change.ClusterARN) != 0 {
 		res += fmt.Sprintf(", ClusterARN: %s", change.ClusterARN)
 	}
+	if change.ReasonCode != "" {
+		res += ", ReasonCode: " + change.ReasonCode
+	}
+	if change.RuntimePlatformVersion != "" {
+		res += ", RuntimePlatformVersion: " + change.RuntimePlatformVersion
+	}
+	if change.LaunchLatency != nil {
+		res += ", LaunchLatency: {" + change.LaunchLatency.String() + "}"
+	}
+	if change.AgentResourcePressure != nil {
+		res += ", AgentResourcePressure: {" + change.AgentResourcePressure.String() + "}"
+	}
+	if change.PauseContainerStatus != nil {
+		res += ", PauseContainerStatus: " + change.PauseContainerStatus.String()
+	}
+	if change.SubmissionDelayedByThrottling {
+		res += ", SubmissionDelayedByThrottling: true"
+	}
+	if change.QueueDelay != 0 {
+		res += ", QueueDelay: " + change.QueueDelay.String()
+	}
+	if change.EventID != "" {
+		res += ", EventID: " + change.EventID
+	}
+	if change.Reconciled {
+		res += ", Reconciled: true"
+	}
 	if change.MetadataGetter != nil && !change.MetadataGetter.GetTaskIsNil() {
-		res += fmt.Sprintf(", Known Sent: %s, PullStartedAt: %s, PullStoppedAt: %s, ExecutionStoppedAt: %s",
-			change.MetadataGetter.GetTaskSentStatusString(),
-			change.MetadataGetter.GetTaskPullStartedAt(),
-			change.MetadataGetter.GetTaskPullStoppedAt(),
-			change.MetadataGetter.GetTaskExecutionStoppedAt())
+		pullStartedAt := change.MetadataGetter.GetTaskPullStartedAt()
+		pullStoppedAt := change.MetadataGetter.GetTaskPullStoppedAt()
+		executionStoppedAt := change.MetadataGetter.GetTaskExecutionStoppedAt()
+		res += fmt.Sprintf(", Known: %s, Sent: %s, PullStartedAt: %s, PullStoppedAt: %s, ExecutionStoppedAt: %s",
+			change.MetadataGetter.GetTaskKnownStatus(), change.MetadataGetter.GetTaskSentStatusString(),
+			pullStartedAt, pullStoppedAt, executionStoppedAt)
+		if pullDuration, ok := elapsed(pullStartedAt, pullStoppedAt); ok {
+			res += ", PullDuration: " + pullDuration.String()
+		}
+		if executionDuration, ok := elapsed(pullStoppedAt, executionStoppedAt); ok {
+			res += ", ExecutionDuration: " + executionDuration.String()
+		}
 	}
 	if change.Attachment != nil {
 		res += ", " + change.Attachment.String()
 	}
+	if change.NetworkConfiguration != nil {
+		res += ", network configuration: " + change.NetworkConfiguration.String()
+	}
 	for _, containerChange := range change.Containers {
 		res += ", container change: " + containerChange.String()
 	}
@@ -146,15 +1312,452 @@ func (change *TaskStateChange) String() string {
 		res += ", managed agent: " + managedAgentChange.String()
 	}
 
+	return RedactSensitiveData(res)
+}
+
+// Diff returns the human readable differences between the change and other, one entry
+// per differing field, in the form "field: old -> new" where old is the value of the
+// receiver. The status, exit code, reason, image digest and the set of network bindings
+// are compared; the order of the bindings is ignored. The metadata getter is not
+// compared. If only one of the changes is nil, a single entry describing the whole
+// change is returned.
+func (c *ContainerStateChange) Diff(other *ContainerStateChange) []string {
+	if c == nil && other == nil {
+		return nil
+	}
+	if c == nil || other == nil {
+		return []string{fmt.Sprintf("change: %s -> %s", containerStateChangeOrNil(c), containerStateChangeOrNil(other))}
+	}
+
+	var diffs []string
+	if c.Status != other.Status {
+		diffs = append(diffs, fmt.Sprintf("status: %s -> %s", c.Status.String(), other.Status.String()))
+	}
+	if !intPtrEqual(c.ExitCode, other.ExitCode) {
+		diffs = append(diffs, fmt.Sprintf("exitCode: %s -> %s", intPtrString(c.ExitCode), intPtrString(other.ExitCode)))
+	}
+	if c.Reason != other.Reason {
+		diffs = append(diffs, fmt.Sprintf("reason: %q -> %q", c.Reason, other.Reason))
+	}
+	if c.ImageDigest != other.ImageDigest {
+		diffs = append(diffs, fmt.Sprintf("imageDigest: %q -> %q", c.ImageDigest, other.ImageDigest))
+	}
+	bindings, otherBindings := networkBindingEntries(c.NetworkBindings), networkBindingEntries(other.NetworkBindings)
+	sort.Strings(bindings)
+	sort.Strings(otherBindings)
+	if strings.Join(bindings, " ") != strings.Join(otherBindings, " ") {
+		diffs = append(diffs, fmt.Sprintf("networkBindings: [%s] -> [%s]",
+			strings.Join(bindings, " "), strings.Join(otherBindings, " ")))
+	}
+	return diffs
+}
+
+// containerStateChangeOrNil renders a possibly nil ContainerStateChange.
+func containerStateChangeOrNil(c *ContainerStateChange) string {
+	if c == nil {
+		return "<nil>"
+	}
+	return "{" + c.String() + "}"
+}
+
+// intPtrEqual returns true if both pointers are nil or point to equal values.
+func intPtrEqual(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// intPtrString renders a possibly nil int pointer.
+func intPtrString(i *int) string {
+	if i == nil {
+		return "<nil>"
+	}
+	return strconv.Itoa(*i)
+}
+
+// IsStatusRegression returns true if the status of the change is earlier in the task
+// lifecycle than the status already sent for the task, as reported by the metadata
+// getter. Such a change, e.g. RUNNING after STOPPED, is rejected by the backend. A change
+// whose task has no known sent status is not a regression.
+func (change *TaskStateChange) IsStatusRegression() bool {
+	if change.MetadataGetter == nil || change.MetadataGetter.GetTaskIsNil() {
+		return false
+	}
+	sentStatus, ok := parseTaskStatus(change.MetadataGetter.GetTaskSentStatusString())
+	if !ok || sentStatus == apitaskstatus.TaskStatusNone {
+		return false
+	}
+	return change.Status < sentStatus
+}
+
+// Submittable is the canonical check of whether the TaskStateChange should be sent to the
+// backend. It composes all the pre-submission checks: the change must refer to a task and
+// must not regress the status already sent for the task. It returns false with the reason,
+// either a StateChangeValidationError or ErrStatusRegression, when the change should be
+// dropped.
+func (change *TaskStateChange) Submittable() (bool, error) {
+	if change.TaskARN == "" {
+		return false, &StateChangeValidationError{Field: "TaskARN", Reason: "must not be empty"}
+	}
+	if change.IsStatusRegression() {
+		return false, fmt.Errorf("%w: task is %s", ErrStatusRegression, change.Status.String())
+	}
+	return true, nil
+}
+
+// parseTaskStatus parses the string representation of a task status.
+func parseTaskStatus(s string) (apitaskstatus.TaskStatus, bool) {
+	var status apitaskstatus.TaskStatus
+	if s == "" {
+		return status, false
+	}
+	encoded, err := json.Marshal(s)
+	if err != nil {
+		return status, false
+	}
+	if err := json.Unmarshal(encoded, &status); err != nil {
+		return status, false
+	}
+	return status, true
+}
+
+// Clone returns a deep copy of the TaskStateChange that can be modified without
+// affecting the original. The container and managed agent changes, including the
+// network bindings and other values they point to, the timestamps and the other
+// optional fields are copied. The ENI attachment and the metadata getter refer to
+// state owned by the agent and are shared with the original.
+func (change *TaskStateChange) Clone() *TaskStateChange {
+	if change == nil {
+		return nil
+	}
+	clone := *change
+	if change.Containers != nil {
+		clone.Containers = make([]*ecs.ContainerStateChange, len(change.Containers))
+		for i, container := range change.Containers {
+			if container != nil {
+				clone.Containers[i] = awsutil.CopyOf(container).(*ecs.ContainerStateChange)
+			}
+		}
+	}
+	if change.ManagedAgents != nil {
+		clone.ManagedAgents = make([]*ecs.ManagedAgentStateChange, len(change.ManagedAgents))
+		for i, managedAgent := range change.ManagedAgents {
+			if managedAgent != nil {
+				clone.ManagedAgents[i] = awsutil.CopyOf(managedAgent).(*ecs.ManagedAgentStateChange)
+			}
+		}
+	}
+	clone.PullStartedAt = copyTime(change.PullStartedAt)
+	clone.PullStoppedAt = copyTime(change.PullStoppedAt)
+	clone.ExecutionStoppedAt = copyTime(change.ExecutionStoppedAt)
+	if change.NetworkConfiguration != nil {
+		networkConfiguration := *change.NetworkConfiguration
+		clone.NetworkConfiguration = &networkConfiguration
+	}
+	if change.LaunchLatency != nil {
+		launchLatency := *change.LaunchLatency
+		clone.LaunchLatency = &launchLatency
+	}
+	if change.AgentResourcePressure != nil {
+		agentResourcePressure := *change.AgentResourcePressure
+		clone.AgentResourcePressure = &agentResourcePressure
+	}
+	if change.PauseContainerStatus != nil {
+		pauseContainerStatus := *change.PauseContainerStatus
+		clone.PauseContainerStatus = &pauseContainerStatus
+	}
+	return &clone
+}
+
+// copyTime returns a pointer to a copy of the given time, or nil if t is nil.
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
+
+// Equals returns true if the two task state changes would result in the same
+// submission to ECS. The metadata getter and timestamps are derived state and are
+// not compared. Container and managed agent changes are compared irrespective of
+// their order, and nil and empty lists are treated as equal.
+func (change *TaskStateChange) Equals(other *TaskStateChange) bool {
+	if change == nil || other == nil {
+		return change == other
+	}
+	if change.TaskARN != other.TaskARN || change.Status != other.Status || change.Reason != other.Reason {
+		return false
+	}
+	containersEqual := unorderedEqual(len(change.Containers), len(other.Containers), func(i, j int) bool {
+		return containerStateChangesEqual(change.Containers[i], other.Containers[j])
+	})
+	if !containersEqual {
+		return false
+	}
+	return unorderedEqual(len(change.ManagedAgents), len(other.ManagedAgents), func(i, j int) bool {
+		return managedAgentStateChangesEqual(change.ManagedAgents[i], other.ManagedAgents[j])
+	})
+}
+
+// unorderedEqual returns true if every element of a list of length n can be paired
+// with a distinct equal element of a list of length m.
+func unorderedEqual(n, m int, equal func(i, j int) bool) bool {
+	if n != m {
+		return false
+	}
+	matched := make([]bool, m)
+	for i := 0; i < n; i++ {
+		found := false
+		for j := 0; j < m; j++ {
+			if !matched[j] && equal(i, j) {
+				matched[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func containerStateChangesEqual(a, b *ecs.ContainerStateChange) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if aws.StringValue(a.ContainerName) != aws.StringValue(b.ContainerName) ||
+		aws.StringValue(a.RuntimeId) != aws.StringValue(b.RuntimeId) ||
+		aws.StringValue(a.Status) != aws.StringValue(b.Status) ||
+		aws.StringValue(a.Reason) != aws.StringValue(b.Reason) ||
+		aws.StringValue(a.ImageDigest) != aws.StringValue(b.ImageDigest) {
+		return false
+	}
+	if (a.ExitCode == nil) != (b.ExitCode == nil) || aws.Int64Value(a.ExitCode) != aws.Int64Value(b.ExitCode) {
+		return false
+	}
+	bindingsEqual := unorderedEqual(len(a.NetworkBindings), len(b.NetworkBindings), func(i, j int) bool {
+		return reflect.DeepEqual(a.NetworkBindings[i], b.NetworkBindings[j])
+	})
+	if !bindingsEqual {
+		return false
+	}
+	return unorderedEqual(len(a.ManagedAgents), len(b.ManagedAgents), func(i, j int) bool {
+		return managedAgentStateChangesEqual(a.ManagedAgents[i], b.ManagedAgents[j])
+	})
+}
+
+func managedAgentStateChangesEqual(a, b *ecs.ManagedAgentStateChange) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return aws.StringValue(a.ContainerName) == aws.StringValue(b.ContainerName) &&
+		aws.StringValue(a.ManagedAgentName) == aws.StringValue(b.ManagedAgentName) &&
+		aws.StringValue(a.Status) == aws.StringValue(b.Status) &&
+		aws.StringValue(a.Reason) == aws.StringValue(b.Reason)
+}
+
+// ManagedAgentsTerminal reports whether all the given managed agent changes are in a
+// terminal status, and returns the container-qualified names, e.g. "app/ExecuteCommandAgent",
+// of the stopped managed agents that reported a failure reason. A task without managed
+// agents has all of them in a terminal status.
+func ManagedAgentsTerminal(managedAgents []*ecs.ManagedAgentStateChange) (bool, []string) {
+	allTerminal := true
+	var failed []string
+	for _, managedAgent := range managedAgents {
+		if managedAgent == nil {
+			continue
+		}
+		if aws.StringValue(managedAgent.Status) != apicontainerstatus.ManagedAgentStopped.String() {
+			allTerminal = false
+			continue
+		}
+		if aws.StringValue(managedAgent.Reason) != "" {
+			failed = append(failed, aws.StringValue(managedAgent.ContainerName)+"/"+
+				aws.StringValue(managedAgent.ManagedAgentName))
+		}
+	}
+	return allTerminal, failed
+}
+
+// ErrEmptyAttachmentStateChange is returned when an AttachmentStateChange has no attachment,
+// or an attachment without an ARN.
+var ErrEmptyAttachmentStateChange = errors.New("empty attachment state change")
+
+// Validate checks that the AttachmentStateChange refers to an attachment, and returns an
+// error wrapping ErrEmptyAttachmentStateChange if it doesn't.
+func (change *AttachmentStateChange) Validate() error {
+	if !change.hasAttachment() {
+		return fmt.Errorf("%w: attachment is nil", ErrEmptyAttachmentStateChange)
+	}
+	if change.GetARN() == "" {
+		return fmt.Errorf("%w: attachment arn is empty", ErrEmptyAttachmentStateChange)
+	}
+	return nil
+}
+
+// Submittable is the canonical check of whether the AttachmentStateChange should be sent
+// to the backend. The status of an attachment change is read from the attachment itself
+// and the status sent for it isn't known here, so only the validation applies. It returns
+// false with the validation error when the change should be dropped.
+func (change *AttachmentStateChange) Submittable() (bool, error) {
+	if err := change.Validate(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Validate checks that the VolumeAttachmentStateChange refers to an attachment, and
+// returns an error wrapping ErrEmptyAttachmentStateChange if it doesn't. A
+// StateChangeValidationError is returned if the volume ID is missing.
+func (change *VolumeAttachmentStateChange) Validate() error {
+	if change.AttachmentARN == "" {
+		return fmt.Errorf("%w: attachment arn is empty", ErrEmptyAttachmentStateChange)
+	}
+	if change.VolumeID == "" {
+		return &StateChangeValidationError{Field: "VolumeID", Reason: "must not be empty"}
+	}
+	return nil
+}
+
+// Submittable is the canonical check of whether the VolumeAttachmentStateChange should be
+// sent to the backend. No sent status is tracked for volume attachments, so only the
+// validation applies. It returns false with the validation error when the change should
+// be dropped.
+func (change *VolumeAttachmentStateChange) Submittable() (bool, error) {
+	if err := change.Validate(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// String returns a human readable string representation of a VolumeAttachmentStateChange.
+func (change *VolumeAttachmentStateChange) String() string {
+	res := fmt.Sprintf("%s -> %s, volumeID=%s", change.AttachmentARN, change.Status.String(), change.VolumeID)
+	if change.EventID != "" {
+		res += ", EventID: " + change.EventID
+	}
 	return res
 }
 
+// elapsed returns the time elapsed between start and end, rounded to the millisecond. It
+// returns false if either time is unset or end is before start.
+func elapsed(start, end time.Time) (time.Duration, bool) {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0, false
+	}
+	return end.Sub(start).Round(time.Millisecond), true
+}
+
+// expiringAttachment is implemented by attachments which must be acknowledged before
+// an expiration time.
+type expiringAttachment interface {
+	GetExpiresAt() time.Time
+}
+
+// attachmentExpiryString renders the expiration time of an attachment along with its
+// remaining time to live as of now, or EXPIRED once it has passed. Nothing is rendered
+// for a zero expiration time.
+func attachmentExpiryString(expiresAt, now time.Time) string {
+	if expiresAt.IsZero() {
+		return ""
+	}
+	res := ", ExpiresAt: " + expiresAt.Format(time.RFC3339)
+	if ttl := expiresAt.Sub(now); ttl > 0 {
+		return res + ", TTL: " + ttl.Round(time.Second).String()
+	}
+	return res + ", EXPIRED"
+}
+
+// GetARN returns the ARN of the attachment of the AttachmentStateChange, whatever its
+// concrete type, or an empty string if there's no attachment.
+func (change *AttachmentStateChange) GetARN() string {
+	if !change.hasAttachment() {
+		return ""
+	}
+	return change.Attachment.GetAttachmentARN()
+}
+
+// hasAttachment returns whether the AttachmentStateChange has an attachment. An interface
+// holding a nil pointer, such as a nil *ni.ENIAttachment, counts as no attachment.
+func (change *AttachmentStateChange) hasAttachment() bool {
+	return change.Attachment != nil && !reflect.ValueOf(change.Attachment).IsNil()
+}
+
+// GetStatusString returns the status of the attachment of the AttachmentStateChange, such
+// as ATTACHED, or an empty string if there's no attachment.
+func (change *AttachmentStateChange) GetStatusString() string {
+	if !change.hasAttachment() {
+		return ""
+	}
+	status := change.Attachment.GetAttachmentStatus()
+	return status.String()
+}
+
 // String returns a human readable string representation of an AttachmentStateChange.
 func (change *AttachmentStateChange) String() string {
-	if change.Attachment != nil {
-		return fmt.Sprintf("%s -> %v, %s", change.Attachment.GetAttachmentARN(),
-			change.Attachment.GetAttachmentStatus(), change.Attachment.String())
+	if !change.hasAttachment() {
+		return ""
+	}
+	res := fmt.Sprintf("%s -> %s, %s", change.GetARN(), change.GetStatusString(), change.Attachment.String())
+	if expiring, ok := change.Attachment.(expiringAttachment); ok {
+		res += attachmentExpiryString(expiring.GetExpiresAt(), _time.Now())
+	}
+	if change.EventID != "" {
+		res += ", EventID: " + change.EventID
 	}
+	return res
+}
 
-	return ""
+// AttachmentStateChangeSet accumulates attachment state changes pending submission. It
+// keeps the latest change of each attachment, keyed by attachment ARN, and is safe for
+// concurrent use. The zero value is an empty set ready to use.
+type AttachmentStateChangeSet struct {
+	lock    sync.Mutex
+	changes map[string]*AttachmentStateChange
+	// arns holds the ARNs of the pending changes in the order they were first added.
+	arns []string
+}
+
+// Add adds the change to the set. A pending change for the same attachment is only
+// replaced if the status of the new change is a valid successor of its status, so that
+// a change processed late can't regress the attachment. Changes that fail validation
+// are ignored.
+func (set *AttachmentStateChangeSet) Add(change *AttachmentStateChange) {
+	if change == nil || change.Validate() != nil {
+		return
+	}
+	arn := change.GetARN()
+
+	set.lock.Lock()
+	defer set.lock.Unlock()
+	if set.changes == nil {
+		set.changes = make(map[string]*AttachmentStateChange)
+	}
+	pending, ok := set.changes[arn]
+	if !ok {
+		set.changes[arn] = change
+		set.arns = append(set.arns, arn)
+		return
+	}
+	pendingStatus := pending.Attachment.GetAttachmentStatus()
+	if pendingStatus.CanTransitionTo(change.Attachment.GetAttachmentStatus()) {
+		set.changes[arn] = change
+	}
+}
+
+// Drain returns the pending changes in the order their attachments were first added, and
+// clears the set.
+func (set *AttachmentStateChangeSet) Drain() []*AttachmentStateChange {
+	set.lock.Lock()
+	defer set.lock.Unlock()
+	drained := make([]*AttachmentStateChange, 0, len(set.arns))
+	for _, arn := range set.arns {
+		drained = append(drained, set.changes[arn])
+	}
+	set.changes = nil
+	set.arns = nil
+	return drained
 }
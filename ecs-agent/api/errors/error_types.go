@@ -60,6 +60,12 @@ type DefaultRetriableError struct {
 	error
 }
 
+// Unwrap returns the wrapped error, so that it can be inspected with errors.Is and
+// errors.As
+func (e *DefaultRetriableError) Unwrap() error {
+	return e.error
+}
+
 // NewRetriableError creates a new DefaultRetriableError object
 func NewRetriableError(retriable Retriable, err error) RetriableError {
 	return &DefaultRetriableError{
@@ -23,6 +23,9 @@ import (
 
 type eventHandler func(...interface{}) error
 
+// EventFilter reports whether an event should be delivered to a subscriber
+type EventFilter func(event interface{}) bool
+
 // EventStream waiting for events and notifying the listeners by invoking
 // the handler that listeners registered
 type EventStream struct {
@@ -58,6 +61,23 @@ func (eventStream *EventStream) Subscribe(name string, handler eventHandler) err
 	return nil
 }
 
+// SubscribeWithFilter adds the handler to be called into EventStream only for
+// the events accepted by the filter
+func (eventStream *EventStream) SubscribeWithFilter(name string, filter EventFilter, handler eventHandler) error {
+	return eventStream.Subscribe(name, func(events ...interface{}) error {
+		var accepted []interface{}
+		for _, event := range events {
+			if filter(event) {
+				accepted = append(accepted, event)
+			}
+		}
+		if len(accepted) == 0 {
+			return nil
+		}
+		return handler(accepted...)
+	})
+}
+
 // broadcast calls all handler's handler function
 func (eventStream *EventStream) broadcast(event interface{}) {
 	eventStream.handlersLock.RLock()
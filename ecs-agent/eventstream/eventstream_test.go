@@ -18,6 +18,7 @@ package eventstream
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -74,6 +75,48 @@ func TestUnsubscribe(t *testing.T) {
 	waiter2.Wait()
 }
 
+// TestSubscribeWithFilter tests the listeners subscribed with
+// filters are only notified of the events they accept
+func TestSubscribeWithFilter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var lock sync.Mutex
+	received := map[string][]interface{}{}
+	waiter := &sync.WaitGroup{}
+	listener := func(name string) func(...interface{}) error {
+		return func(events ...interface{}) error {
+			lock.Lock()
+			defer lock.Unlock()
+			received[name] = append(received[name], events...)
+			waiter.Done()
+			return nil
+		}
+	}
+	prefixFilter := func(prefix string) EventFilter {
+		return func(event interface{}) bool {
+			return strings.HasPrefix(event.(string), prefix)
+		}
+	}
+
+	eventStream := NewEventStream("TestSubscribeWithFilter", ctx)
+	eventStream.SubscribeWithFilter("listener1", prefixFilter("tenant1/"), listener("listener1"))
+	eventStream.SubscribeWithFilter("listener2", prefixFilter("tenant2/"), listener("listener2"))
+	eventStream.StartListening()
+
+	waiter.Add(3)
+	for _, event := range []string{"tenant1/a", "tenant2/b", "tenant1/c"} {
+		err := eventStream.WriteToEventStream(event)
+		assert.NoError(t, err)
+	}
+	waiter.Wait()
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.ElementsMatch(t, []interface{}{"tenant1/a", "tenant1/c"}, received["listener1"])
+	assert.ElementsMatch(t, []interface{}{"tenant2/b"}, received["listener2"])
+}
+
 // TestCancelEventStream tests the event stream can
 // be closed by context
 func TestCancelEventStream(t *testing.T) {
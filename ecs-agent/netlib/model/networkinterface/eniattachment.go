@@ -169,6 +169,12 @@ func (eni *ENIAttachment) GetAttachmentStatus() attachment.AttachmentStatus {
 	return eni.Status
 }
 
+func (eni *ENIAttachment) GetExpiresAt() time.Time {
+	eni.guard.RLock()
+	defer eni.guard.RUnlock()
+	return eni.ExpiresAt
+}
+
 // stringUnsafe returns a string representation of the ENI Attachment
 func (eni *ENIAttachment) stringUnsafe() string {
 	// skip TaskArn field for instance level eni attachment since it won't have a task arn